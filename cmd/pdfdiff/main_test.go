@@ -1,12 +1,58 @@
 package main
 
 import (
+	"fmt"
 	"github.com/aelbrecht/pdfdump/external/pdf"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func BenchmarkPDFComparing(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		pdf.Compare("./test/input_a.pdf", "./test/input_b.pdf", false)
+		if _, err := pdf.Compare("./test/input_a.pdf", "./test/input_b.pdf"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// writeLargeCorpus writes two documents of the given number of pages, the
+// right one has every tenth page edited and an extra page in the middle.
+func writeLargeCorpus(b *testing.B, pages int) (string, string) {
+	b.Helper()
+	left := make([][]string, 0, pages)
+	right := make([][]string, 0, pages+1)
+	for i := 0; i < pages; i++ {
+		lines := []string{fmt.Sprintf("Page %d", i+1), "Lorem ipsum dolor sit amet", fmt.Sprintf("Total %d.00", i*7)}
+		left = append(left, lines)
+		if i%10 == 0 {
+			lines = []string{lines[0], "Lorem ipsum dolor sit amet, consectetur", lines[2]}
+		}
+		if i == pages/2 {
+			right = append(right, []string{"Inserted page"})
+		}
+		right = append(right, lines)
+	}
+
+	dir := b.TempDir()
+	leftPath := filepath.Join(dir, "left.pdf")
+	rightPath := filepath.Join(dir, "right.pdf")
+	if err := os.WriteFile(leftPath, pdfgen.TextDocument(left...).Bytes(), 0644); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(rightPath, pdfgen.TextDocument(right...).Bytes(), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return leftPath, rightPath
+}
+
+func BenchmarkPDFComparingLarge(b *testing.B) {
+	leftPath, rightPath := writeLargeCorpus(b, 5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pdf.Compare(leftPath, rightPath); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
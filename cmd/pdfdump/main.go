@@ -1,28 +1,11 @@
 package main
 
 import (
-	"fmt"
-	"github.com/aelbrecht/pdfdump/external/pdf"
-	"github.com/aelbrecht/pdfdump/internal/token"
-	"log"
+	"github.com/aelbrecht/pdfdump/internal/cli"
 	"os"
 )
 
+// pdfdump is kept for existing scripts, it runs pdftool dump.
 func main() {
-
-	if len(os.Args) != 2 {
-		log.Println("error: expected one argument")
-		os.Exit(1)
-	}
-
-	f, err := os.Open(os.Args[1])
-	if err != nil {
-		fmt.Println(err)
-	}
-
-	parser := pdf.NewParser(token.NewScanner(f))
-	parser.Parse()
-	_ = f.Close()
-
-	fmt.Print(parser.PDF().String())
+	cli.Run("dump", os.Args[1:])
 }
@@ -1,40 +1,11 @@
 package main
 
 import (
-	"fmt"
-	"github.com/aelbrecht/pdfdump/external/pdf"
-	"github.com/aelbrecht/pdfdump/internal/token"
-	"log"
+	"github.com/aelbrecht/pdfdump/internal/cli"
 	"os"
-	"path"
-	"strings"
 )
 
-func parsePDF(filePath string) {
-
-	f, err := os.Open(filePath)
-	if err != nil {
-		fmt.Println(err)
-	}
-
-	scanner := token.NewScanner(f)
-	parser := pdf.NewParser(scanner)
-	parser.Parse()
-
-	_ = f.Close()
-
-	dirName, fileName := path.Split(filePath)
-	fileName = strings.TrimSuffix(fileName, path.Ext(fileName))
-	o, err := os.Create(path.Join(dirName, fileName+".txt"))
-	if err != nil {
-		log.Fatalln(err)
-	}
-	parser.Dump(o)
-	_ = o.Close()
-}
-
+// pdfexpand is kept for existing scripts, it runs pdftool expand -write.
 func main() {
-	for _, arg := range os.Args[1:] {
-		parsePDF(arg)
-	}
+	cli.Run("expand", append([]string{"-write"}, os.Args[1:]...))
 }
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/cli"
+	"os"
+)
+
+// pdftext is kept for existing scripts, it runs pdftool text.
+func main() {
+	cli.Run("text", os.Args[1:])
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/cli"
+	"os"
+)
+
+func main() {
+	cli.Main(os.Args[1:])
+}
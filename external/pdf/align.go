@@ -0,0 +1,140 @@
+package pdf
+
+// Arrays with more element pairs than this left after trimming their common
+// ends are aligned by position, the alignment needs a score for every pair.
+const maxAlignmentCells = 1 << 20
+
+// alignment pairs the element at index Left of one sequence with the element
+// at index Right of the other, an index of -1 marks an element without
+// counterpart.
+type alignment struct {
+	Left  int
+	Right int
+	Score float64
+}
+
+// alignSequences aligns two sequences so that the total score of the paired
+// elements is maximal while keeping their order, like a longest common
+// subsequence weighted by similarity. Elements only pair if they score above
+// zero, so insertions and deletions do not shift the rest of the sequence.
+// Deletions come before insertions at the same position.
+func alignSequences(n int, m int, score func(i int, j int) float64) []alignment {
+	alignments := make([]alignment, 0, n+m)
+
+	// Identical ends are paired right away
+	start := 0
+	for start < n && start < m {
+		s := score(start, start)
+		if s != 1 {
+			break
+		}
+		alignments = append(alignments, alignment{start, start, s})
+		start++
+	}
+	suffix := make([]alignment, 0)
+	for n-len(suffix) > start && m-len(suffix) > start {
+		i := n - len(suffix) - 1
+		j := m - len(suffix) - 1
+		s := score(i, j)
+		if s != 1 {
+			break
+		}
+		suffix = append(suffix, alignment{i, j, s})
+	}
+	endLeft := n - len(suffix)
+	endRight := m - len(suffix)
+	rows := endLeft - start
+	cols := endRight - start
+
+	if rows*cols > maxAlignmentCells {
+		alignments = append(alignments, alignPositions(start, endLeft, endRight, score)...)
+	} else {
+		alignments = append(alignments, alignScores(start, rows, cols, score)...)
+	}
+	for k := len(suffix) - 1; k >= 0; k-- {
+		alignments = append(alignments, suffix[k])
+	}
+	return alignments
+}
+
+// alignScores aligns the given range of two sequences by dynamic programming.
+func alignScores(start int, rows int, cols int, score func(i int, j int) float64) []alignment {
+	scores := make([][]float64, rows)
+	for i := range scores {
+		scores[i] = make([]float64, cols)
+		for j := range scores[i] {
+			scores[i][j] = score(start+i, start+j)
+		}
+	}
+	// best[i][j] is the best total score of the first i and j elements
+	best := make([][]float64, rows+1)
+	for i := range best {
+		best[i] = make([]float64, cols+1)
+	}
+	for i := 1; i <= rows; i++ {
+		for j := 1; j <= cols; j++ {
+			b := best[i-1][j]
+			if best[i][j-1] > b {
+				b = best[i][j-1]
+			}
+			if s := scores[i-1][j-1]; s > 0 && best[i-1][j-1]+s > b {
+				b = best[i-1][j-1] + s
+			}
+			best[i][j] = b
+		}
+	}
+
+	// Walk back from the end, collecting the alignment in reverse
+	reversed := make([]alignment, 0, rows+cols)
+	i, j := rows, cols
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && scores[i-1][j-1] > 0 && best[i][j] == best[i-1][j-1]+scores[i-1][j-1]:
+			reversed = append(reversed, alignment{start + i - 1, start + j - 1, scores[i-1][j-1]})
+			i--
+			j--
+		case j > 0 && (i == 0 || best[i][j] == best[i][j-1]):
+			reversed = append(reversed, alignment{-1, start + j - 1, 0})
+			j--
+		default:
+			reversed = append(reversed, alignment{start + i - 1, -1, 0})
+			i--
+		}
+	}
+	alignments := make([]alignment, len(reversed))
+	for k := range reversed {
+		alignments[k] = reversed[len(reversed)-1-k]
+	}
+	return alignments
+}
+
+// alignPositions pairs the elements of the given range by position.
+func alignPositions(start int, endLeft int, endRight int, score func(i int, j int) float64) []alignment {
+	alignments := make([]alignment, 0)
+	for k := start; k < endLeft || k < endRight; k++ {
+		switch {
+		case k >= endLeft:
+			alignments = append(alignments, alignment{-1, k, 0})
+		case k >= endRight:
+			alignments = append(alignments, alignment{k, -1, 0})
+		default:
+			if s := score(k, k); s > 0 {
+				alignments = append(alignments, alignment{k, k, s})
+			} else {
+				alignments = append(alignments, alignment{k, -1, 0}, alignment{-1, k, 0})
+			}
+		}
+	}
+	return alignments
+}
+
+// alignArrays aligns the elements of two arrays by their MatchTypes score.
+func alignArrays(left *Array, right *Array, opts *MatchOptions) []alignment {
+	return alignSequences(len(left.Value), len(right.Value), func(i int, j int) float64 {
+		score := MatchTypes(left.Value[i], right.Value[j], opts)
+		if score > 1 {
+			panic("score must be below or equal to 1")
+		}
+		return score
+	})
+}
@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"testing"
+)
+
+func labels(values ...string) *Array {
+	a := &Array{}
+	for _, v := range values {
+		a.Value = append(a.Value, NewLabel("/"+v))
+	}
+	return a
+}
+
+func TestAlignArrays(t *testing.T) {
+	left := labels("A", "B", "C", "D")
+	right := labels("X", "A", "B", "D")
+
+	// One inserted and one removed element leave the others aligned
+	if score := MatchTypes(left, right, &MatchOptions{}); score != 0.75 {
+		t.Errorf("expected score 0.75, got %f", score)
+	}
+
+	expected := []alignment{{-1, 0, 0}, {0, 1, 1}, {1, 2, 1}, {2, -1, 0}, {3, 3, 1}}
+	alignments := alignArrays(left, right, &MatchOptions{})
+	if len(alignments) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, alignments)
+	}
+	for i := range expected {
+		if alignments[i] != expected[i] {
+			t.Errorf("alignment %d: expected %v, got %v", i, expected[i], alignments[i])
+		}
+	}
+
+	kinds := []DiffKind{DiffInserted, DiffEqual, DiffEqual, DiffDeleted, DiffEqual}
+	keys := []string{"[0]", "[0]", "[1]", "[2]", "[3]"}
+	nodes := diffArrays(left, right, nil)
+	if len(nodes) != len(kinds) {
+		t.Fatalf("expected %d nodes, got %d", len(kinds), len(nodes))
+	}
+	for i, node := range nodes {
+		if node.Kind != kinds[i] || node.Key != keys[i] {
+			t.Errorf("node %d: expected %s %s, got %s %s", i, keys[i], kinds[i], node.Key, node.Kind)
+		}
+	}
+
+	// Replaced elements are shown as modified
+	nodes = diffArrays(labels("A", "B"), labels("A", "C"), nil)
+	if len(nodes) != 2 || nodes[1].Kind != DiffModified {
+		t.Errorf("expected the second element to be modified")
+	}
+}
@@ -0,0 +1,115 @@
+package pdf
+
+import (
+	"context"
+	"math"
+)
+
+// assign solves the assignment problem with the Hungarian method. It returns
+// the column assigned to every row of the weight matrix such that the total
+// weight is maximal, rows left without a column get -1. Ties are resolved by
+// the order of rows and columns, so the result is deterministic.
+func assign(weights [][]float64) []int {
+	rows, _ := assignContext(newMatching(context.Background(), &CompareOptions{}), weights)
+	return rows
+}
+
+// assignContext is assign stopping early when the context of the matching is
+// done, the progress is reported per row.
+func assignContext(m *matching, weights [][]float64) ([]int, error) {
+	n := len(weights)
+	rows := make([]int, n)
+	for i := range rows {
+		rows[i] = -1
+	}
+	if n == 0 || len(weights[0]) == 0 {
+		return rows, nil
+	}
+	cols := len(weights[0])
+
+	// The method needs at least as many columns as rows
+	if n > cols {
+		transposed := make([][]float64, cols)
+		for j := range transposed {
+			transposed[j] = make([]float64, n)
+			for i := range weights {
+				transposed[j][i] = weights[i][j]
+			}
+		}
+		columns, err := assignContext(m, transposed)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range columns {
+			if i >= 0 {
+				rows[i] = j
+			}
+		}
+		return rows, nil
+	}
+
+	// Potentials and matching are indexed from 1, index 0 is the free row
+	u := make([]float64, n+1)
+	v := make([]float64, cols+1)
+	p := make([]int, cols+1)
+	way := make([]int, cols+1)
+	minv := make([]float64, cols+1)
+	used := make([]bool, cols+1)
+	for i := 1; i <= n; i++ {
+		if err := m.ctx.Err(); err != nil {
+			return nil, err
+		}
+		m.report(StageAssign, i-1, n)
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.Inf(1)
+			used[j] = false
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= cols; j++ {
+				if used[j] {
+					continue
+				}
+				cost := -weights[i0-1][j-1] - u[i0] - v[j]
+				if cost < minv[j] {
+					minv[j] = cost
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= cols; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	for j := 1; j <= cols; j++ {
+		if p[j] != 0 {
+			rows[p[j]-1] = j - 1
+		}
+	}
+	m.report(StageAssign, n, n)
+	return rows, nil
+}
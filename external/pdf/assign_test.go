@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	tests := []struct {
+		name     string
+		weights  [][]float64
+		expected []int
+	}{
+		{
+			name:     "greedy is suboptimal",
+			weights:  [][]float64{{0.9, 0.8}, {0.85, 0.1}},
+			expected: []int{1, 0},
+		},
+		{
+			name:     "more rows than columns",
+			weights:  [][]float64{{0.2}, {0.7}, {0.5}},
+			expected: []int{-1, 0, -1},
+		},
+		{
+			name:     "more columns than rows",
+			weights:  [][]float64{{0.1, 0.3, 0.9}},
+			expected: []int{2},
+		},
+		{
+			name:     "ties keep the order",
+			weights:  [][]float64{{1, 1}, {1, 1}},
+			expected: []int{0, 1},
+		},
+		{
+			name:     "empty",
+			weights:  [][]float64{},
+			expected: []int{},
+		},
+	}
+	for _, test := range tests {
+		got := assign(test.weights)
+		if len(got) != len(test.expected) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+				break
+			}
+		}
+	}
+}
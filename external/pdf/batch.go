@@ -0,0 +1,150 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	FileEqual   = "equal"
+	FileChanged = "changed"
+	FileAdded   = "added"
+	FileRemoved = "removed"
+	FileFailed  = "failed"
+)
+
+// FileComparison is the comparison of a file present in either directory,
+// paired by its path relative to the directory. Files only present on one
+// side or that failed to compare have no comparison.
+type FileComparison struct {
+	Path       string      `json:"path"`
+	Status     string      `json:"status"`
+	Comparison *Comparison `json:"comparison,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// DirectoryComparison holds the comparisons of the files of two directories,
+// sorted by path.
+type DirectoryComparison struct {
+	LeftPath  string            `json:"left"`
+	RightPath string            `json:"right"`
+	Files     []*FileComparison `json:"files"`
+}
+
+// Equal reports whether both directories hold the same files with equal
+// documents.
+func (d *DirectoryComparison) Equal() bool {
+	for _, file := range d.Files {
+		if file.Status != FileEqual {
+			return false
+		}
+	}
+	return true
+}
+
+// listFiles returns the paths relative to the directory of the files matching
+// the pattern. Patterns without a separator match the name of the file,
+// others the relative path.
+func listFiles(dir string, pattern string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		name := entry.Name()
+		if strings.Contains(pattern, "/") {
+			name = rel
+		}
+		ok, err := filepath.Match(pattern, name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			files[rel] = true
+		}
+		return nil
+	})
+	return files, err
+}
+
+// CompareDirectories pairs the files matching the pattern, e.g. "*.pdf", by
+// their path relative to both directories and compares every pair. Pairs are
+// compared concurrently on the workers of the options, one worker per pair.
+// Progress reports the files done.
+func CompareDirectories(ctx context.Context, leftDir string, rightDir string, pattern string, options *CompareOptions) (*DirectoryComparison, error) {
+	leftFiles, err := listFiles(leftDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	rightFiles, err := listFiles(rightDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareComparison(options); err != nil {
+		return nil, err
+	}
+
+	result := &DirectoryComparison{LeftPath: leftDir, RightPath: rightDir}
+	for p := range leftFiles {
+		file := &FileComparison{Path: p, Status: FileRemoved}
+		if rightFiles[p] {
+			file.Status = ""
+		}
+		result.Files = append(result.Files, file)
+	}
+	for p := range rightFiles {
+		if !leftFiles[p] {
+			result.Files = append(result.Files, &FileComparison{Path: p, Status: FileAdded})
+		}
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	pairs := make([]*FileComparison, 0)
+	for _, file := range result.Files {
+		if file.Status == "" {
+			pairs = append(pairs, file)
+		}
+	}
+	single := *options
+	single.Workers = 1
+	single.Progress = nil
+	m := newMatching(ctx, options)
+	err = m.parallel(StageFiles, len(pairs), func(i int) {
+		file := pairs[i]
+		defer func() {
+			if r := recover(); r != nil {
+				file.Status = FileFailed
+				file.Error = fmt.Sprint(r)
+			}
+		}()
+		comparison, err := compareFiles(ctx, filepath.Join(leftDir, file.Path), filepath.Join(rightDir, file.Path), &single)
+		if err != nil {
+			file.Status = FileFailed
+			file.Error = err.Error()
+			return
+		}
+		file.Comparison = comparison
+		file.Status = FileChanged
+		if comparison.Equal() {
+			file.Status = FileEqual
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
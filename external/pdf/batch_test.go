@@ -0,0 +1,64 @@
+package pdf
+
+import (
+	"context"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeDocument(t *testing.T, p string, d *pdfgen.Document) {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, d.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareDirectories(t *testing.T) {
+	left := t.TempDir()
+	right := t.TempDir()
+	hello := pdfgen.TextDocument([]string{"Hello world"})
+	writeDocument(t, filepath.Join(left, "same.pdf"), hello)
+	writeDocument(t, filepath.Join(right, "same.pdf"), hello)
+	writeDocument(t, filepath.Join(left, "sub", "changed.pdf"), hello)
+	writeDocument(t, filepath.Join(right, "sub", "changed.pdf"), pdfgen.TextDocument([]string{"Hello brave world"}))
+	writeDocument(t, filepath.Join(left, "removed.pdf"), hello)
+	writeDocument(t, filepath.Join(right, "added.pdf"), hello)
+	writeDocument(t, filepath.Join(right, "notes.txt"), hello)
+	writeDocument(t, filepath.Join(left, "corrupt.pdf"), hello)
+	if err := os.WriteFile(filepath.Join(right, "corrupt.pdf"), hello.Bytes()[:200], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := CompareDirectories(context.Background(), left, right, "*.pdf", DefaultCompareOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FileComparison{
+		{Path: "added.pdf", Status: FileAdded},
+		{Path: "corrupt.pdf", Status: FileFailed},
+		{Path: "removed.pdf", Status: FileRemoved},
+		{Path: "same.pdf", Status: FileEqual},
+		{Path: "sub/changed.pdf", Status: FileChanged},
+	}
+	if len(result.Files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(result.Files))
+	}
+	for i, file := range result.Files {
+		if file.Path != expected[i].Path || file.Status != expected[i].Status {
+			t.Errorf("file %d: expected %s %s, got %s %s", i, expected[i].Path, expected[i].Status, file.Path, file.Status)
+		}
+		if (file.Comparison != nil) != (file.Status == FileEqual || file.Status == FileChanged) {
+			t.Errorf("%s: unexpected comparison", file.Path)
+		}
+		if (file.Error != "") != (file.Status == FileFailed) {
+			t.Errorf("%s: unexpected error %q", file.Path, file.Error)
+		}
+	}
+	if result.Equal() {
+		t.Error("expected the directories to differ")
+	}
+}
@@ -2,138 +2,157 @@ package pdf
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/aelbrecht/pdfdump/internal/token"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	"math"
 	"os"
+	"sort"
 	"strings"
 )
 
 type Comparison struct {
-	LeftPath    string
-	RightPath   string
-	LeftOutput  string
-	RightOutput string
+	LeftPath       string             `json:"left"`
+	RightPath      string             `json:"right"`
+	LeftOutput     string             `json:"-"`
+	RightOutput    string             `json:"-"`
+	Matches        []*ObjectMatch     `json:"matches"`
+	LeftUnmatched  []ObjectIdentifier `json:"leftUnmatched"`
+	RightUnmatched []ObjectIdentifier `json:"rightUnmatched"`
+	Pages          []*PageComparison  `json:"pages"`
+	Summary        Summary            `json:"summary"`
+	LeftDocument   *PDF               `json:"-"`
+	RightDocument  *PDF               `json:"-"`
+	// options are the match options the objects were diffed with
+	options *MatchOptions
 }
 
-type comparer struct {
-	buffer               bytes.Buffer
-	currentLine          string
-	currentRemoveLine    string
-	currentAddLine       string
-	currentLineHasRemove bool
-	currentLineHasAdd    bool
+// ObjectMatch is a pair of objects considered to be the same object in both
+// documents, with the paths at which they differ.
+type ObjectMatch struct {
+	Left    ObjectIdentifier `json:"left"`
+	Right   ObjectIdentifier `json:"right"`
+	Score   float64          `json:"score"`
+	Changes []Change         `json:"changes"`
+	Diff    *DiffNode        `json:"-"`
 }
 
-func (f *comparer) emit() {
-	if !f.currentLineHasAdd && !f.currentLineHasRemove {
-		f.buffer.WriteString(fmt.Sprintf("= %s\n", f.currentLine))
-	}
-	if f.currentLineHasAdd {
-		xs := strings.Split(f.currentAddLine, "\n")
-		for _, x := range xs {
-			f.buffer.WriteString(fmt.Sprintf("+ %s\n", x))
-		}
-	}
-	if f.currentLineHasRemove {
-		xs := strings.Split(f.currentRemoveLine, "\n")
-		for _, x := range xs {
-			f.buffer.WriteString(fmt.Sprintf("- %s\n", x))
-		}
-	}
+type Summary struct {
+	LeftObjects    int     `json:"leftObjects"`
+	RightObjects   int     `json:"rightObjects"`
+	ExactMatches   int     `json:"exactMatches"`
+	CloseMatches   int     `json:"closeMatches"`
+	DistantMatches int     `json:"distantMatches"`
+	LeftUnmatched  int     `json:"leftUnmatched"`
+	RightUnmatched int     `json:"rightUnmatched"`
+	Changed        int     `json:"changed"`
+	MatchRate      float64 `json:"matchRate"`
 }
 
-func (c *Comparison) String() string {
-	cmp := comparer{}
-	return cmp.diff(c.LeftOutput, c.RightOutput)
+// Equal reports whether every object was matched without changes.
+func (c *Comparison) Equal() bool {
+	return c.Summary.LeftUnmatched == 0 && c.Summary.RightUnmatched == 0 && c.Summary.Changed == 0
 }
 
-func (f *comparer) diff(left string, right string) string {
-	differ := diffmatchpatch.New()
-	output := differ.DiffMain(left, right, false)
-	for _, d := range output {
-		f.parse(&d)
+func (c *Comparison) String() string {
+	r := diffRenderer{}
+	for index, match := range c.Matches {
+		score := int(math.Round(match.Score * 100))
+		r.write("= ", fmt.Sprintf("# Object (%d) (%d%%)", index, score))
+		r.object(match.Diff)
+	}
+	for _, id := range c.LeftUnmatched {
+		r.write("- ", "# Object Unmatched")
+		r.write("- ", strings.TrimSuffix(c.RenderObject(c.LeftDocument.Objects[id.Hash()]), "\n"))
+	}
+	for _, id := range c.RightUnmatched {
+		r.write("+ ", "# Object Unmatched")
+		r.write("+ ", strings.TrimSuffix(c.RenderObject(c.RightDocument.Objects[id.Hash()]), "\n"))
 	}
-	f.emit()
-	return f.buffer.String()
+	return r.buffer.String()
 }
 
-func (f *comparer) parse(d *diffmatchpatch.Diff) {
-	switch d.Type {
-	case diffmatchpatch.DiffEqual:
-		for _, c := range d.Text {
-			if c == '\n' {
-				f.emit()
-				f.currentLineHasRemove = false
-				f.currentLineHasAdd = false
-				f.currentRemoveLine = ""
-				f.currentAddLine = ""
-				f.currentLine = ""
-			} else {
-				f.currentRemoveLine += string(c)
-				f.currentAddLine += string(c)
-				f.currentLine += string(c)
-			}
-		}
-	case diffmatchpatch.DiffDelete:
-		f.currentLineHasRemove = true
-		f.currentRemoveLine += d.Text
-	case diffmatchpatch.DiffInsert:
-		f.currentLineHasAdd = true
-		f.currentAddLine += d.Text
-	}
+// RenderObject renders an object of either document with the rounding of
+// the comparison.
+func (c *Comparison) RenderObject(o *Object) string {
+	return o.format(c.options)
 }
 
-func parsePDF(filePath string) *PDF {
+func parsePDF(filePath string) (*PDF, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
+	}
+	defer f.Close()
+	scanner, err := token.NewScanner(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
 	}
-	scanner := token.NewScanner(f)
 	parser := NewParser(scanner)
-	parser.Parse()
-	_ = f.Close()
-	return parser.PDF()
+	if err := parser.Parse(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return parser.PDF(), nil
 }
 
-func approxMatch(left *PDF, right *PDF, leftResolved map[string]bool, rightResolved map[string]bool, bestMatches map[string]string, bestScores map[string]float64, opts *MatchOptions) int {
-	iteration := 0
+func approxMatch(m *matching, stage string, left *PDF, right *PDF, leftIndex *fingerprintIndex, rightIndex *fingerprintIndex, leftResolved map[string]bool, rightResolved map[string]bool, bestMatches map[string]string, bestScores map[string]float64, opts *MatchOptions) (int, error) {
+	leftObjects := left.SortedObjects()
 	statApprox := 0
 	for len(leftResolved) != len(left.Objects) || len(rightResolved) != len(right.Objects) {
 		leftMatch := make(map[string]float64)
 		rightMatch := make(map[string]float64)
 		localMatches := make(map[string]string)
 
-		for k1, o1 := range left.Objects {
-
-			// Skip perfect matched objects
-			if leftResolved[k1] {
-				continue
+		// Skip perfect matched objects
+		pending := make([]*Object, 0)
+		for _, o1 := range leftObjects {
+			if !leftResolved[o1.Identifier.Hash()] {
+				pending = append(pending, o1)
 			}
+		}
 
-			bestScore := 0.0
-			bestKey := ""
-			for k2, o2 := range right.Objects {
-
-				// Skip perfect matched objects
-				if rightResolved[k2] {
-					continue
-				}
-
-				// Calculate match
-				score := MatchTypes(o1, o2, opts)
-				if score < 0.1 {
-					continue
-				}
-
-				// Select best candidate based on distance
-				if score > bestScore {
-					bestScore = score
-					bestKey = k2
+		// The best candidate of every object only depends on the resolved
+		// objects of the previous round, so they are searched in parallel
+		candidateKeys := make([]string, len(pending))
+		candidateScores := make([]float64, len(pending))
+		err := m.parallel(stage, len(pending), func(i int) {
+			o1 := pending[i]
+			search := func(candidates []*Object) {
+				for _, o2 := range candidates {
+					k2 := o2.Identifier.Hash()
+
+					// Skip perfect matched objects
+					if rightResolved[k2] {
+						continue
+					}
+
+					// Calculate match
+					score := MatchTypes(o1, o2, opts)
+					if score < 0.1 {
+						continue
+					}
+
+					// Select best candidate based on distance
+					if score > candidateScores[i] {
+						candidateScores[i] = score
+						candidateKeys[i] = k2
+					}
 				}
 			}
+			f := leftIndex.fingerprints[o1]
+			search(rightIndex.candidates(f))
+			if candidateKeys[i] == "" {
+				search(rightIndex.fallbackCandidates(f))
+			}
+		})
+		if err != nil {
+			return statApprox, err
+		}
 
+		for i, o1 := range pending {
+			k1 := o1.Identifier.Hash()
+			bestKey := candidateKeys[i]
+			bestScore := candidateScores[i]
 			if bestKey != "" {
 				if leftMatch[k1] < bestScore && rightMatch[bestKey] < bestScore {
 					localMatches[k1] = bestKey
@@ -147,8 +166,13 @@ func approxMatch(left *PDF, right *PDF, leftResolved map[string]bool, rightResol
 			break
 		}
 
-		for k1, k2 := range localMatches {
-			if leftMatch[k1] == rightMatch[k2] {
+		// Every round pairs at least the best scoring candidate, a round
+		// pairing nothing would repeat itself
+		paired := statApprox
+		for _, o1 := range pending {
+			k1 := o1.Identifier.Hash()
+			k2, ok := localMatches[k1]
+			if ok && leftMatch[k1] == rightMatch[k2] && !rightResolved[k2] {
 				bestMatches[k1] = k2
 				bestScores[k1] = leftMatch[k1]
 				leftResolved[k1] = true
@@ -156,45 +180,87 @@ func approxMatch(left *PDF, right *PDF, leftResolved map[string]bool, rightResol
 				statApprox++
 			}
 		}
-
-		iteration++
-		if iteration > 100 {
-			panic("infinite loop detected")
+		if statApprox == paired {
+			break
 		}
 	}
 
-	return statApprox
+	return statApprox, nil
 }
 
-func Compare(leftPath string, rightPath string, verbose bool) *Comparison {
+func sortIdentifiers(ids []ObjectIdentifier) {
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].ObjectNumber != ids[j].ObjectNumber {
+			return ids[i].ObjectNumber < ids[j].ObjectNumber
+		}
+		return ids[i].ObjectGeneration < ids[j].ObjectGeneration
+	})
+}
 
-	HideRandomKeys = true
-	HideVariableData = true
-	HideIdentifiers = true
-	HideStreamLength = true
-	TrimFontPrefix = true
+const (
+	StrategyGreedy = "greedy"
+	StrategyGlobal = "global"
+	StrategyGraph  = "graph"
+)
 
-	left := parsePDF(leftPath)
-	right := parsePDF(rightPath)
+// Objects at the same depth are preferred when the global strategy has to
+// choose between pairs of equal score.
+const depthPreference = 1e-6
+
+type CompareOptions struct {
+	// Strategy selects how objects are paired. The greedy strategy locks the
+	// first perfect match and then pairs the best candidates in rounds, the
+	// global strategy maximizes the total score of all pairs. The graph
+	// strategy does the same with scores that also account for the objects
+	// each object is linked to.
+	Strategy string
+	// Threshold is the lowest score at which the global strategies still pair
+	// two objects, below it objects are reported as unmatched.
+	Threshold float64
+	// Workers is the number of goroutines scoring objects, zero uses one per
+	// CPU.
+	Workers int
+	// Scorers overrides how objects are scored, nil keeps the default scoring.
+	Scorers *Scorers
+	// Progress is called as the stages of the comparison advance, from the
+	// worker goroutines but never concurrently.
+	Progress func(Progress)
+	// Tolerance applies to every number without a tolerance for its key.
+	Tolerance Tolerance
+	// Tolerances of numbers by the key of the dictionary entry they belong
+	// to, see MatchOptions.
+	Tolerances map[string]Tolerance
+	// Rules are applied to both documents after parsing and their tolerances
+	// take precedence over Tolerances, nil applies no rules.
+	Rules *Rules
+}
 
-	n1 := len(left.Objects)
-	n2 := len(right.Objects)
-	if n1 > n2 {
-		n2, n1 = n1, n2
+func DefaultCompareOptions() *CompareOptions {
+	return &CompareOptions{
+		Strategy:  StrategyGreedy,
+		Threshold: 0.1,
 	}
+}
 
-	if verbose {
-		fmt.Printf("comparing %d with %d objects\n", len(left.Objects), len(right.Objects))
+// greedyMatch locks perfect matches, then pairs objects at the same depth
+// and finally the remaining objects at any depth.
+func greedyMatch(m *matching, left *PDF, right *PDF, leftResolved map[string]bool, rightResolved map[string]bool, bestMatches map[string]string, bestScores map[string]float64, summary *Summary) error {
+	leftObjects := left.SortedObjects()
+	leftIndex := newFingerprintIndex(leftObjects)
+	rightIndex := newFingerprintIndex(right.SortedObjects())
+	resolved := func(o *Object) bool {
+		return rightResolved[o.Identifier.Hash()]
 	}
 
-	bestMatches := make(map[string]string)
-	bestMatchScores := make(map[string]float64)
-	leftResolved := make(map[string]bool)
-	rightResolved := make(map[string]bool)
-
-	matches := 0
-	for k1, o1 := range left.Objects {
-		for k2, o2 := range right.Objects {
+	// Perfect matches are locked in order, which leaves little to run in
+	// parallel since the first candidate mostly matches
+	for i, o1 := range leftObjects {
+		if err := m.ctx.Err(); err != nil {
+			return err
+		}
+		k1 := o1.Identifier.Hash()
+		for _, o2 := range rightIndex.exactCandidates(leftIndex.fingerprints[o1], resolved) {
+			k2 := o2.Identifier.Hash()
 
 			// Skip perfect matched objects
 			if rightResolved[k2] {
@@ -202,7 +268,7 @@ func Compare(leftPath string, rightPath string, verbose bool) *Comparison {
 			}
 
 			// Calculate match
-			opts := MatchOptions{}
+			opts := m.options(false)
 			score := MatchTypes(o1, o2, &opts)
 
 			// Lock perfect matches
@@ -210,68 +276,314 @@ func Compare(leftPath string, rightPath string, verbose bool) *Comparison {
 				leftResolved[k1] = true
 				rightResolved[k2] = true
 				bestMatches[k1] = k2
-				bestMatchScores[k1] = score
-				matches++
+				bestScores[k1] = score
+				summary.ExactMatches++
 				break
 			}
 		}
-	}
-	if matches > 0 && verbose {
-		fmt.Printf("exact matches:\t%d\n", matches)
+		m.report(StageExact, i+1, len(leftObjects))
 	}
 
-	opts := MatchOptions{MatchDepth: true}
-	matches = approxMatch(left, right, leftResolved, rightResolved, bestMatches, bestMatchScores, &opts)
-	if matches > 0 && verbose {
-		fmt.Printf("close matches:\t%d\n", matches)
+	var err error
+	opts := m.options(true)
+	summary.CloseMatches, err = approxMatch(m, StageClose, left, right, leftIndex, rightIndex, leftResolved, rightResolved, bestMatches, bestScores, &opts)
+	if err != nil {
+		return err
 	}
 
 	opts.MatchDepth = false
-	matches = approxMatch(left, right, leftResolved, rightResolved, bestMatches, bestMatchScores, &opts)
-	if matches > 0 && verbose {
-		fmt.Printf("distant matches:\t%d\n", matches)
+	summary.DistantMatches, err = approxMatch(m, StageDistant, left, right, leftIndex, rightIndex, leftResolved, rightResolved, bestMatches, bestScores, &opts)
+	return err
+}
+
+// localScores scores every pair of objects in isolation, in the order of
+// SortedObjects. Pairs of a different class are only scored when no pair of
+// the same class reaches the threshold, the others are left at zero.
+func localScores(m *matching, leftObjects []*Object, rightObjects []*Object) ([][]float64, error) {
+	leftIndex := newFingerprintIndex(leftObjects)
+	rightIndex := newFingerprintIndex(rightObjects)
+	columns := make(map[*Object]int, len(rightObjects))
+	for j, o := range rightObjects {
+		columns[o] = j
 	}
 
-	leftBuffer := bytes.Buffer{}
-	rightBuffer := bytes.Buffer{}
+	opts := m.options(false)
+	scores := make([][]float64, len(leftObjects))
+	err := m.parallel(StageScores, len(leftObjects), func(i int) {
+		o1 := leftObjects[i]
+		scores[i] = make([]float64, len(rightObjects))
+		f := leftIndex.fingerprints[o1]
+		matched := false
+		for _, o2 := range rightIndex.candidates(f) {
+			scores[i][columns[o2]] = MatchTypes(o1, o2, &opts)
+			matched = matched || scores[i][columns[o2]] >= m.threshold
+		}
+		if !matched {
+			for _, o2 := range rightIndex.fallbackCandidates(f) {
+				scores[i][columns[o2]] = MatchTypes(o1, o2, &opts)
+			}
+		}
+	})
+	return scores, err
+}
 
-	index := 0
-	for k1, k2 := range bestMatches {
-		score := int(math.Round(bestMatchScores[k1] * 100))
-		_, _ = leftBuffer.WriteString(fmt.Sprintf("# Object (%d) (%d%%)\n", index, score))
-		_, _ = rightBuffer.WriteString(fmt.Sprintf("# Object (%d) (%d%%)\n", index, score))
-		_, _ = leftBuffer.WriteString(left.Objects[k1].String())
-		_, _ = rightBuffer.WriteString(right.Objects[k2].String())
-		index++
+// globalMatch pairs objects such that the sum of the weights of all pairs is
+// maximal, pairs weighing less than the threshold are left unmatched. Scores
+// are the local scores reported for the pairs. Pairs
+// at the same depth count as close matches, like in the greedy strategy.
+func globalMatch(m *matching, left *PDF, right *PDF, scores [][]float64, weights [][]float64, threshold float64, leftResolved map[string]bool, rightResolved map[string]bool, bestMatches map[string]string, bestScores map[string]float64, summary *Summary) error {
+	leftObjects := left.SortedObjects()
+	rightObjects := right.SortedObjects()
+
+	candidates := make([][]float64, len(weights))
+	for i := range weights {
+		candidates[i] = make([]float64, len(weights[i]))
+		for j, weight := range weights[i] {
+			if weight == 0 || weight < threshold {
+				continue
+			}
+			candidates[i][j] = weight
+			if leftObjects[i].Depth == rightObjects[j].Depth {
+				candidates[i][j] += depthPreference
+			}
+		}
 	}
 
-	fstUnmatched := 0
+	assignment, err := assignContext(m, candidates)
+	if err != nil {
+		return err
+	}
+	for i, j := range assignment {
+		if j < 0 || candidates[i][j] == 0 {
+			continue
+		}
+		o1 := leftObjects[i]
+		o2 := rightObjects[j]
+		k1 := o1.Identifier.Hash()
+		k2 := o2.Identifier.Hash()
+		leftResolved[k1] = true
+		rightResolved[k2] = true
+		bestMatches[k1] = k2
+		bestScores[k1] = scores[i][j]
+		switch {
+		case scores[i][j] == 1:
+			summary.ExactMatches++
+		case o1.Depth == o2.Depth:
+			summary.CloseMatches++
+		default:
+			summary.DistantMatches++
+		}
+	}
+	return nil
+}
+
+// Compare matches the objects of two documents with the default options. It
+// replaces Compare(leftPath, rightPath, verbose), the counts that were printed
+// are in the Summary of the result.
+func Compare(leftPath string, rightPath string) (*Comparison, error) {
+	return CompareWithOptions(leftPath, rightPath, DefaultCompareOptions())
+}
+
+// CompareWithOptions matches the objects of two documents, it fails when a
+// document cannot be parsed or the rules are invalid.
+func CompareWithOptions(leftPath string, rightPath string, options *CompareOptions) (*Comparison, error) {
+	return CompareContext(context.Background(), leftPath, rightPath, options)
+}
+
+// CompareContext matches the objects of two documents, it stops early and
+// returns the error of the context when the context is done first.
+func CompareContext(ctx context.Context, leftPath string, rightPath string, options *CompareOptions) (*Comparison, error) {
+	if err := prepareComparison(options); err != nil {
+		return nil, err
+	}
+	return compareFiles(ctx, leftPath, rightPath, options)
+}
+
+// prepareComparison switches on the normalizations of comparisons, documents
+// are parsed afterwards.
+func prepareComparison(options *CompareOptions) error {
+
+	HideRandomKeys = true
+	HideVariableData = true
+	HideIdentifiers = true
+	HideStreamLength = true
+	TrimFontPrefix = true
+
+	if options.Rules != nil {
+		return options.Rules.compile()
+	}
+	return nil
+}
+
+// compareFiles parses and compares two documents once the comparison is
+// prepared, it is safe to call concurrently.
+func compareFiles(ctx context.Context, leftPath string, rightPath string, options *CompareOptions) (*Comparison, error) {
+	left, err := parsePDF(leftPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	right, err := parsePDF(rightPath)
+	if err != nil {
+		return nil, err
+	}
+	if options.Rules != nil {
+		if err := options.Rules.Apply(left); err != nil {
+			return nil, err
+		}
+		if err := options.Rules.Apply(right); err != nil {
+			return nil, err
+		}
+	}
+	result, err := CompareDocumentsContext(ctx, left, right, options)
+	if err != nil {
+		return nil, err
+	}
+	result.LeftPath = leftPath
+	result.RightPath = rightPath
+	return result, nil
+}
+
+// CompareDocuments matches the objects of two parsed documents.
+func CompareDocuments(left *PDF, right *PDF, options *CompareOptions) (*Comparison, error) {
+	return CompareDocumentsContext(context.Background(), left, right, options)
+}
+
+func CompareDocumentsContext(ctx context.Context, left *PDF, right *PDF, options *CompareOptions) (*Comparison, error) {
+	leftDocument, rightDocument := left, right
+	left, right = left.matchable(), right.matchable()
+
+	n1 := len(left.Objects)
+	n2 := len(right.Objects)
+	if n1 > n2 {
+		n2, n1 = n1, n2
+	}
+
+	summary := Summary{
+		LeftObjects:  len(left.Objects),
+		RightObjects: len(right.Objects),
+	}
+
+	bestMatches := make(map[string]string)
+	bestMatchScores := make(map[string]float64)
+	leftResolved := make(map[string]bool)
+	rightResolved := make(map[string]bool)
+
+	m := newMatching(ctx, options)
+	var err error
+	switch options.Strategy {
+	case StrategyGlobal:
+		var scores [][]float64
+		scores, err = localScores(m, left.SortedObjects(), right.SortedObjects())
+		if err == nil {
+			err = globalMatch(m, left, right, scores, scores, options.Threshold, leftResolved, rightResolved, bestMatches, bestMatchScores, &summary)
+		}
+	case StrategyGraph:
+		var scores, weights [][]float64
+		scores, err = localScores(m, left.SortedObjects(), right.SortedObjects())
+		if err == nil {
+			weights, err = floodScores(m, left, right, scores)
+		}
+		if err == nil {
+			err = globalMatch(m, left, right, scores, weights, options.Threshold, leftResolved, rightResolved, bestMatches, bestMatchScores, &summary)
+		}
+	default:
+		err = greedyMatch(m, left, right, leftResolved, rightResolved, bestMatches, bestMatchScores, &summary)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts := m.options(false)
+	result := &Comparison{
+		LeftUnmatched:  make([]ObjectIdentifier, 0),
+		RightUnmatched: make([]ObjectIdentifier, 0),
+		LeftDocument:   leftDocument,
+		RightDocument:  rightDocument,
+		options:        &opts,
+	}
+
+	keys := make([]string, 0, len(bestMatches))
+	for k1 := range bestMatches {
+		keys = append(keys, k1)
+	}
+	result.Matches = make([]*ObjectMatch, len(keys))
+	err = m.parallel(StageDiff, len(keys), func(i int) {
+		o1 := left.Objects[keys[i]]
+		o2 := right.Objects[bestMatches[keys[i]]]
+		diff := DiffObjects(o1, o2, &opts)
+		result.Matches[i] = &ObjectMatch{
+			Left:    o1.Identifier,
+			Right:   o2.Identifier,
+			Score:   bestMatchScores[keys[i]],
+			Changes: diff.Changes(),
+			Diff:    diff,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range result.Matches {
+		if len(match.Changes) > 0 {
+			summary.Changed++
+		}
+	}
+	sort.Slice(result.Matches, func(i, j int) bool {
+		a := result.Matches[i].Left
+		b := result.Matches[j].Left
+		if a.ObjectNumber != b.ObjectNumber {
+			return a.ObjectNumber < b.ObjectNumber
+		}
+		return a.ObjectGeneration < b.ObjectGeneration
+	})
+
 	for k, v := range left.Objects {
 		if !leftResolved[k] {
-			_, _ = leftBuffer.WriteString(fmt.Sprintf("# Object Unmatched\n"))
-			_, _ = leftBuffer.WriteString(v.String())
-			fstUnmatched++
+			result.LeftUnmatched = append(result.LeftUnmatched, v.Identifier)
 		}
 	}
+	sortIdentifiers(result.LeftUnmatched)
 
-	sndUnmatched := 0
 	for k, v := range right.Objects {
 		if !rightResolved[k] {
-			_, _ = rightBuffer.WriteString(fmt.Sprintf("# Object Unmatched\n"))
-			_, _ = rightBuffer.WriteString(v.String())
-			sndUnmatched++
+			result.RightUnmatched = append(result.RightUnmatched, v.Identifier)
 		}
 	}
+	sortIdentifiers(result.RightUnmatched)
 
-	if verbose {
-		success := 1.0 - (math.Max(float64(fstUnmatched), float64(sndUnmatched))-float64(n2-n1))/float64(n1)
-		fmt.Printf("match rate:\t%d%%\n", int(math.Round(success*100)))
+	leftBuffer := bytes.Buffer{}
+	rightBuffer := bytes.Buffer{}
+
+	for index, match := range result.Matches {
+		score := int(math.Round(match.Score * 100))
+		_, _ = leftBuffer.WriteString(fmt.Sprintf("# Object (%d) (%d%%)\n", index, score))
+		_, _ = rightBuffer.WriteString(fmt.Sprintf("# Object (%d) (%d%%)\n", index, score))
+		_, _ = leftBuffer.WriteString(left.Objects[match.Left.Hash()].String())
+		_, _ = rightBuffer.WriteString(right.Objects[match.Right.Hash()].String())
+	}
+
+	for _, id := range result.LeftUnmatched {
+		_, _ = leftBuffer.WriteString(fmt.Sprintf("# Object Unmatched\n"))
+		_, _ = leftBuffer.WriteString(left.Objects[id.Hash()].String())
+	}
+
+	for _, id := range result.RightUnmatched {
+		_, _ = rightBuffer.WriteString(fmt.Sprintf("# Object Unmatched\n"))
+		_, _ = rightBuffer.WriteString(right.Objects[id.Hash()].String())
 	}
 
-	return &Comparison{
-		LeftPath:    leftPath,
-		RightPath:   rightPath,
-		LeftOutput:  leftBuffer.String(),
-		RightOutput: rightBuffer.String(),
+	summary.LeftUnmatched = len(result.LeftUnmatched)
+	summary.RightUnmatched = len(result.RightUnmatched)
+	summary.MatchRate = 1
+	if n1 > 0 {
+		summary.MatchRate = 1.0 - (math.Max(float64(summary.LeftUnmatched), float64(summary.RightUnmatched))-float64(n2-n1))/float64(n1)
 	}
+
+	result.Pages = result.ComparePages()
+	result.LeftOutput = leftBuffer.String()
+	result.RightOutput = rightBuffer.String()
+	result.Summary = summary
+	return result, nil
 }
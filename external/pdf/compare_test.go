@@ -0,0 +1,151 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareContext(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}, []string{"Second page"}))
+	right := parseDocument(t, pdfgen.TextDocument([]string{"Hello brave world"}, []string{"Second page"}))
+
+	for _, strategy := range []string{StrategyGreedy, StrategyGlobal, StrategyGraph} {
+		stages := make(map[string]bool)
+		options := DefaultCompareOptions()
+		options.Strategy = strategy
+		options.Workers = 4
+		options.Progress = func(p Progress) {
+			if p.Done > p.Total {
+				t.Errorf("%s: progress beyond total: %+v", strategy, p)
+			}
+			stages[p.Stage] = true
+		}
+		result, err := CompareDocumentsContext(context.Background(), left, right, options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", strategy, err)
+		}
+		if len(result.Matches) != len(left.Objects) {
+			t.Errorf("%s: expected %d matches, got %d", strategy, len(left.Objects), len(result.Matches))
+		}
+		if !stages[StageDiff] {
+			t.Errorf("%s: no progress reported for the diff stage", strategy)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := CompareDocumentsContext(ctx, left, right, options); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", strategy, err)
+		}
+	}
+}
+
+func TestComparisonJSON(t *testing.T) {
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.pdf")
+	rightPath := filepath.Join(dir, "right.pdf")
+	writeDocument(t, leftPath, pdfgen.TextDocument([]string{"Hello world"}))
+	writeDocument(t, rightPath, pdfgen.TextDocument([]string{"Hello world"}, []string{"Second page"}))
+
+	result, err := Compare(leftPath, rightPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"left", "right", "matches", "leftUnmatched", "rightUnmatched", "pages", "summary"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("missing field %s in %s", key, data)
+		}
+	}
+	for _, key := range []string{"LeftOutput", "LeftDocument", "options"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("unexpected field %s", key)
+		}
+	}
+
+	var decoded Comparison
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.LeftPath != leftPath || decoded.RightPath != rightPath {
+		t.Errorf("unexpected paths %s and %s", decoded.LeftPath, decoded.RightPath)
+	}
+	if decoded.Summary != result.Summary {
+		t.Errorf("expected summary %+v, got %+v", result.Summary, decoded.Summary)
+	}
+	if len(decoded.Matches) != len(result.Matches) || len(decoded.RightUnmatched) != len(result.RightUnmatched) {
+		t.Fatalf("expected %d matches and %d unmatched, got %d and %d",
+			len(result.Matches), len(result.RightUnmatched), len(decoded.Matches), len(decoded.RightUnmatched))
+	}
+	for i, match := range decoded.Matches {
+		if match.Left != result.Matches[i].Left || match.Right != result.Matches[i].Right || len(match.Changes) != len(result.Matches[i].Changes) {
+			t.Errorf("match %d: expected %+v, got %+v", i, result.Matches[i], match)
+		}
+	}
+	if len(decoded.Pages) != 2 || decoded.Pages[1].Status != PageAdded {
+		t.Errorf("expected the second page to be added, got %+v", decoded.Pages)
+	}
+}
+
+func TestGreedyMatchManyRounds(t *testing.T) {
+	// every object prefers the same candidate, a round pairs only one
+	const n = 150
+	left := pdfgen.New()
+	right := pdfgen.New()
+	for i := 0; i < n; i++ {
+		left.Add(fmt.Sprintf("<< /Size %d >>", 500+i))
+		right.Add(fmt.Sprintf("<< /Size %d >>", 1000+i))
+	}
+	result, err := CompareDocuments(parseDocument(t, left), parseDocument(t, right), DefaultCompareOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != n || result.Summary.CloseMatches != n {
+		t.Errorf("expected %d close matches, got %+v", n, result.Summary)
+	}
+}
+
+func TestMatchChangedType(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		path  string
+	}{
+		{"changed subtype", "<< /Type /Annot /Subtype /Link /Rect [ 0 0 10 10 ] /Border [ 0 0 1 ] >>", "<< /Type /Annot /Subtype /Square /Rect [ 0 0 10 10 ] /Border [ 0 0 1 ] >>", "/Subtype"},
+		{"added type", "<< /Subtype /Link /Rect [ 0 0 10 10 ] /Border [ 0 0 1 ] >>", "<< /Type /Annot /Subtype /Link /Rect [ 0 0 10 10 ] /Border [ 0 0 1 ] >>", "/Type"},
+	}
+	for _, test := range tests {
+		left := pdfgen.New()
+		left.Add(test.left)
+		right := pdfgen.New()
+		right.Add(test.right)
+		for _, strategy := range []string{StrategyGreedy, StrategyGlobal, StrategyGraph} {
+			options := DefaultCompareOptions()
+			options.Strategy = strategy
+			result, err := CompareDocuments(parseDocument(t, left), parseDocument(t, right), options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Matches) != 1 {
+				t.Errorf("%s, %s: expected the objects to match, got %+v", test.name, strategy, result.Summary)
+				continue
+			}
+			if changes := result.Matches[0].Changes; len(changes) != 1 || changes[0].Path != test.path {
+				t.Errorf("%s, %s: expected a change of %s, got %v", test.name, strategy, test.path, changes)
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single operator of a content stream with its operands.
+type Operation struct {
+	Operator string
+	Operands []ObjectType
+}
+
+type contentLexer struct {
+	data   []byte
+	offset int
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *contentLexer) skipWhitespace() {
+	for l.offset < len(l.data) {
+		c := l.data[l.offset]
+		if c == '%' {
+			for l.offset < len(l.data) && l.data[l.offset] != '\n' && l.data[l.offset] != '\r' {
+				l.offset++
+			}
+			continue
+		}
+		if !isWhitespace(c) {
+			return
+		}
+		l.offset++
+	}
+}
+
+func (l *contentLexer) regular() string {
+	start := l.offset
+	for l.offset < len(l.data) && !isWhitespace(l.data[l.offset]) && !isDelimiter(l.data[l.offset]) {
+		l.offset++
+	}
+	return string(l.data[start:l.offset])
+}
+
+func (l *contentLexer) literalString() *Text {
+	start := l.offset
+	depth := 0
+	for l.offset < len(l.data) {
+		c := l.data[l.offset]
+		l.offset++
+		switch c {
+		case '\\':
+			l.offset++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return NewText(string(l.data[start:l.offset]))
+			}
+		}
+	}
+	return NewText(string(l.data[start:]) + ")")
+}
+
+func (l *contentLexer) hexString() *Text {
+	start := l.offset
+	end := bytes.IndexByte(l.data[start:], '>')
+	if end < 0 {
+		l.offset = len(l.data)
+		return NewText(string(l.data[start:]) + ">")
+	}
+	l.offset = start + end + 1
+	return NewText(string(l.data[start:l.offset]))
+}
+
+// skipInlineImage moves past the binary data of an inline image, which ends
+// at the first EI operator surrounded by whitespace.
+func (l *contentLexer) skipInlineImage() {
+	l.offset++
+	for l.offset+2 <= len(l.data) {
+		if l.data[l.offset] == 'E' && l.data[l.offset+1] == 'I' &&
+			isWhitespace(l.data[l.offset-1]) &&
+			(l.offset+2 == len(l.data) || isWhitespace(l.data[l.offset+2])) {
+			l.offset += 2
+			return
+		}
+		l.offset++
+	}
+	l.offset = len(l.data)
+}
+
+// ParseContent splits a decoded content stream into its operations.
+func ParseContent(data []byte) []Operation {
+	l := &contentLexer{data: data}
+	operations := make([]Operation, 0)
+	operands := make([]ObjectType, 0)
+
+	// Containers under construction, arrays hold their elements and
+	// dictionaries their alternating keys and values.
+	type container struct {
+		isDict bool
+		items  []ObjectType
+	}
+	stack := make([]*container, 0)
+	push := func(o ObjectType) {
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			top.items = append(top.items, o)
+		} else {
+			operands = append(operands, o)
+		}
+	}
+
+	for {
+		l.skipWhitespace()
+		if l.offset >= len(l.data) {
+			break
+		}
+		c := l.data[l.offset]
+		switch {
+		case c == '(':
+			push(l.literalString())
+		case c == '<' && l.offset+1 < len(l.data) && l.data[l.offset+1] == '<':
+			l.offset += 2
+			stack = append(stack, &container{isDict: true})
+		case c == '>' && l.offset+1 < len(l.data) && l.data[l.offset+1] == '>':
+			l.offset += 2
+			if len(stack) == 0 || !stack[len(stack)-1].isDict {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pairs := make([]KeyValuePair, 0, len(top.items)/2)
+			for i := 0; i+1 < len(top.items); i += 2 {
+				pairs = append(pairs, KeyValuePair{K: top.items[i], V: top.items[i+1]})
+			}
+			push(NewDictionary(pairs))
+		case c == '<':
+			push(l.hexString())
+		case c == '[':
+			l.offset++
+			stack = append(stack, &container{})
+		case c == ']':
+			l.offset++
+			if len(stack) == 0 || stack[len(stack)-1].isDict {
+				continue
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			push(NewArray(top.items))
+		case c == '/':
+			l.offset++
+			push(NewLabel("/" + decodeName(l.regular())))
+		case c == '{' || c == '}' || c == ')' || c == '>':
+			// PostScript procedures are not used in content streams
+			l.offset++
+		default:
+			word := l.regular()
+			if v, err := strconv.ParseFloat(word, 64); err == nil {
+				push(NewNumber(v))
+				continue
+			}
+			switch word {
+			case "true":
+				push(NewBoolean(true))
+				continue
+			case "false":
+				push(NewBoolean(false))
+				continue
+			case "null":
+				push(NewNull())
+				continue
+			}
+			stack = stack[:0]
+			operations = append(operations, Operation{Operator: word, Operands: operands})
+			operands = make([]ObjectType, 0)
+			if word == "ID" {
+				l.skipInlineImage()
+			}
+		}
+	}
+	return operations
+}
+
+func decodeName(name string) string {
+	if !strings.ContainsRune(name, '#') {
+		return name
+	}
+	output := make([]byte, 0, len(name))
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if b, err := hex.DecodeString(name[i+1 : i+3]); err == nil {
+				output = append(output, b[0])
+				i += 2
+				continue
+			}
+		}
+		output = append(output, name[i])
+	}
+	return string(output)
+}
+
+// Bytes returns the bytes encoded by a literal or hexadecimal string.
+func (s *Text) Bytes() []byte {
+	if len(s.Value) < 2 {
+		return []byte{}
+	}
+	if s.Value[0] == '<' {
+		output, _ := decodeASCIIHex([]byte(s.Value[1:]))
+		return output
+	}
+	raw := s.Value[1 : len(s.Value)-1]
+	output := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c != '\\' || i+1 >= len(raw) {
+			output = append(output, c)
+			continue
+		}
+		i++
+		switch raw[i] {
+		case 'n':
+			output = append(output, '\n')
+		case 'r':
+			output = append(output, '\r')
+		case 't':
+			output = append(output, '\t')
+		case 'b':
+			output = append(output, '\b')
+		case 'f':
+			output = append(output, '\f')
+		case '\r':
+			if i+1 < len(raw) && raw[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+			// line continuation
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			v := 0
+			j := 0
+			for ; j < 3 && i+j < len(raw) && raw[i+j] >= '0' && raw[i+j] <= '7'; j++ {
+				v = v*8 + int(raw[i+j]-'0')
+			}
+			i += j - 1
+			output = append(output, byte(v))
+		default:
+			output = append(output, raw[i])
+		}
+	}
+	return output
+}
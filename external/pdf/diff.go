@@ -0,0 +1,465 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffInserted
+	DiffDeleted
+	DiffModified
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffInserted:
+		return "inserted"
+	case DiffDeleted:
+		return "deleted"
+	case DiffModified:
+		return "modified"
+	default:
+		return "equal"
+	}
+}
+
+// DiffNode is a node of the structural difference between two objects. Nodes
+// of dictionary entries keep the entries themselves, since their values are
+// normalized by key when rendered.
+type DiffNode struct {
+	Kind      DiffKind
+	Key       string
+	Left      ObjectType
+	Right     ObjectType
+	LeftPair  *KeyValuePair
+	RightPair *KeyValuePair
+	Children  []*DiffNode
+	// options the objects were diffed with, set on the root
+	options *MatchOptions
+}
+
+// DiffObjects walks two matched objects in parallel, aligning dictionary
+// entries by key and array elements by similarity. Numbers are compared with
+// the tolerances and rendered with the rounding of the options, which may be
+// nil.
+func DiffObjects(left *Object, right *Object, opts *MatchOptions) *DiffNode {
+	root := &DiffNode{Left: left, Right: right, options: opts}
+	for i := 0; i < len(left.Children) || i < len(right.Children); i++ {
+		var l, r ObjectType
+		if i < len(left.Children) {
+			l = left.Children[i]
+		}
+		if i < len(right.Children) {
+			r = right.Children[i]
+		}
+		key := ""
+		_, leftStream := l.(*Stream)
+		_, rightStream := r.(*Stream)
+		if leftStream || rightStream {
+			key = "stream"
+		} else if i > 0 {
+			key = fmt.Sprintf("[%d]", i)
+		}
+		root.Children = append(root.Children, diffValues(l, r, key, opts))
+	}
+	root.Kind = childrenKind(root.Children)
+	return root
+}
+
+func childrenKind(children []*DiffNode) DiffKind {
+	for _, child := range children {
+		if child.Kind != DiffEqual {
+			return DiffModified
+		}
+	}
+	return DiffEqual
+}
+
+func diffValues(left ObjectType, right ObjectType, key string, opts *MatchOptions) *DiffNode {
+	node := &DiffNode{Key: key, Left: left, Right: right}
+	if left == nil {
+		node.Kind = DiffInserted
+		return node
+	}
+	if right == nil {
+		node.Kind = DiffDeleted
+		return node
+	}
+	switch l := left.(type) {
+	case *Dictionary:
+		if r, ok := right.(*Dictionary); ok {
+			node.Children = diffDictionaries(l, r, opts)
+			node.Kind = childrenKind(node.Children)
+			return node
+		}
+	case *Array:
+		if r, ok := right.(*Array); ok {
+			node.Children = diffArrays(l, r, opts)
+			node.Kind = childrenKind(node.Children)
+			return node
+		}
+	}
+	if format(left, 0, opts) != format(right, 0, opts) && !withinTolerance(left, right, opts) {
+		node.Kind = DiffModified
+	}
+	return node
+}
+
+// diffArrays aligns the elements of two arrays like MatchTypes, so inserted
+// and removed elements do not shift the rest. A run of removed elements
+// followed by a run of inserted ones is paired up by position, as values that
+// were replaced.
+func diffArrays(left *Array, right *Array, opts *MatchOptions) []*DiffNode {
+	alignOpts := &MatchOptions{MatchReferences: true}
+	if opts != nil {
+		alignOpts.Scorers = opts.Scorers
+		alignOpts.Tolerance = opts.Tolerance
+		alignOpts.Tolerances = opts.Tolerances
+	}
+	alignments := alignArrays(left, right, alignOpts)
+	children := make([]*DiffNode, 0, len(alignments))
+	for k := 0; k < len(alignments); {
+		pair := alignments[k]
+		if pair.Left != -1 && pair.Right != -1 {
+			children = append(children, diffValues(left.Value[pair.Left], right.Value[pair.Right], fmt.Sprintf("[%d]", pair.Left), opts))
+			k++
+			continue
+		}
+		deleted := k
+		for k < len(alignments) && alignments[k].Right == -1 {
+			k++
+		}
+		inserted := k
+		for k < len(alignments) && alignments[k].Left == -1 {
+			k++
+		}
+		replaced := inserted - deleted
+		if k-inserted < replaced {
+			replaced = k - inserted
+		}
+		for i := 0; i < replaced; i++ {
+			l := alignments[deleted+i].Left
+			r := alignments[inserted+i].Right
+			children = append(children, diffValues(left.Value[l], right.Value[r], fmt.Sprintf("[%d]", l), opts))
+		}
+		for _, pair := range alignments[deleted+replaced : inserted] {
+			children = append(children, diffValues(left.Value[pair.Left], nil, fmt.Sprintf("[%d]", pair.Left), opts))
+		}
+		for _, pair := range alignments[inserted+replaced : k] {
+			children = append(children, diffValues(nil, right.Value[pair.Right], fmt.Sprintf("[%d]", pair.Right), opts))
+		}
+	}
+	return children
+}
+
+// groupByKey groups dictionary entries by their normalized key, keeping the
+// dictionary order within each group.
+func groupByKey(d *Dictionary) map[string][]*KeyValuePair {
+	groups := make(map[string][]*KeyValuePair)
+	for i := range d.Value {
+		key := d.Value[i].Key()
+		groups[key] = append(groups[key], &d.Value[i])
+	}
+	return groups
+}
+
+func diffDictionaries(left *Dictionary, right *Dictionary, opts *MatchOptions) []*DiffNode {
+	leftGroups := groupByKey(left)
+	rightGroups := groupByKey(right)
+	keys := make([]string, 0)
+	for key := range leftGroups {
+		keys = append(keys, key)
+	}
+	for key := range rightGroups {
+		if _, ok := leftGroups[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	children := make([]*DiffNode, 0)
+	for _, key := range keys {
+		l := leftGroups[key]
+		r := rightGroups[key]
+		for i := 0; i < len(l) || i < len(r); i++ {
+			var node *DiffNode
+			switch {
+			case i >= len(l):
+				node = &DiffNode{Kind: DiffInserted, Key: "/" + r[i].K.String(), Right: r[i].V}
+			case i >= len(r):
+				node = &DiffNode{Kind: DiffDeleted, Key: "/" + l[i].K.String(), Left: l[i].V}
+			case isContainer(l[i].V) && isContainer(r[i].V):
+				node = diffValues(l[i].V, r[i].V, "/"+l[i].K.String(), opts)
+			default:
+				node = &DiffNode{Key: "/" + l[i].K.String(), Left: l[i].V, Right: r[i].V}
+				if l[i].value(0, opts) != r[i].value(0, opts) && !withinTolerance(l[i].V, r[i].V, opts) {
+					node.Kind = DiffModified
+				}
+			}
+			if i < len(l) {
+				node.LeftPair = l[i]
+			}
+			if i < len(r) {
+				node.RightPair = r[i]
+			}
+			children = append(children, node)
+		}
+	}
+	return children
+}
+
+func isContainer(o ObjectType) bool {
+	switch o.(type) {
+	case *Dictionary, *Array:
+		return true
+	}
+	return false
+}
+
+const (
+	ChangeModified = "modified"
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+)
+
+// Change is a difference at a single path of a matched object pair, e.g.
+// /Resources/Font/F1/BaseFont. Added and removed paths leave the missing
+// side empty.
+type Change struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+func (c *Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: + %s", c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: - %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// summarize renders a value on a single line, containers only show their size.
+func summarize(o ObjectType, opts *MatchOptions) string {
+	switch v := o.(type) {
+	case *Dictionary:
+		return fmt.Sprintf("Dict( size:%d )", len(v.Value))
+	case *Array:
+		return fmt.Sprintf("Array( size:%d )", len(v.Value))
+	default:
+		return format(o, 0, opts)
+	}
+}
+
+// summarizePair renders the value of a dictionary entry on a single line,
+// applying the normalizations tied to its key.
+func summarizePair(p *KeyValuePair, opts *MatchOptions) string {
+	switch p.V.(type) {
+	case *Dictionary, *Array:
+		return summarize(p.V, opts)
+	default:
+		return p.value(0, opts)
+	}
+}
+
+// Changes flattens the difference into the list of changed paths.
+func (n *DiffNode) Changes() []Change {
+	changes := make([]Change, 0)
+	n.collectChanges("", &changes, n.options)
+	return changes
+}
+
+func (n *DiffNode) summary(left bool, opts *MatchOptions) string {
+	if left {
+		if n.LeftPair != nil {
+			return summarizePair(n.LeftPair, opts)
+		}
+		return summarize(n.Left, opts)
+	}
+	if n.RightPair != nil {
+		return summarizePair(n.RightPair, opts)
+	}
+	return summarize(n.Right, opts)
+}
+
+func (n *DiffNode) collectChanges(path string, changes *[]Change, opts *MatchOptions) {
+	path += n.Key
+	switch n.Kind {
+	case DiffEqual:
+		return
+	case DiffInserted:
+		*changes = append(*changes, Change{Path: path, Kind: ChangeAdded, New: n.summary(false, opts)})
+		return
+	case DiffDeleted:
+		*changes = append(*changes, Change{Path: path, Kind: ChangeRemoved, Old: n.summary(true, opts)})
+		return
+	}
+	if len(n.Children) == 0 {
+		*changes = append(*changes, Change{Path: path, Kind: ChangeModified, Old: n.summary(true, opts), New: n.summary(false, opts)})
+		return
+	}
+	for _, child := range n.Children {
+		child.collectChanges(path, changes, opts)
+	}
+}
+
+// diffRenderer writes a difference as lines prefixed with "=", "-" or "+",
+// laid out like the string rendering of the objects. Numbers are rounded like
+// the options tell, which may be nil.
+type diffRenderer struct {
+	buffer strings.Builder
+	opts   *MatchOptions
+}
+
+func (r *diffRenderer) write(prefix string, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		r.buffer.WriteString(prefix)
+		r.buffer.WriteString(line)
+		r.buffer.WriteString("\n")
+	}
+}
+
+// pair writes two renderings of the same node, marking only the lines that
+// differ when both have the same shape.
+func (r *diffRenderer) pair(left string, right string) {
+	if left == right {
+		r.write("= ", left)
+		return
+	}
+	leftLines := strings.Split(left, "\n")
+	rightLines := strings.Split(right, "\n")
+	if len(leftLines) != len(rightLines) {
+		r.write("- ", left)
+		r.write("+ ", right)
+		return
+	}
+	for i := range leftLines {
+		if leftLines[i] == rightLines[i] {
+			r.write("= ", leftLines[i])
+		} else {
+			r.write("- ", leftLines[i])
+			r.write("+ ", rightLines[i])
+		}
+	}
+}
+
+// render returns one side of a node as it appears in the string rendering.
+func (n *DiffNode) render(left bool, depth int, opts *MatchOptions) string {
+	if left {
+		if n.LeftPair != nil {
+			return padding(depth) + n.LeftPair.format(depth, opts)
+		}
+		return padding(depth) + format(n.Left, depth, opts)
+	}
+	if n.RightPair != nil {
+		return padding(depth) + n.RightPair.format(depth, opts)
+	}
+	return padding(depth) + format(n.Right, depth, opts)
+}
+
+// bounds returns the opening and closing line of a container node.
+func (n *DiffNode) bounds(left bool, depth int) (string, string) {
+	value := n.Right
+	pair := n.RightPair
+	if left {
+		value = n.Left
+		pair = n.LeftPair
+	}
+	prefix := padding(depth)
+	if pair != nil {
+		prefix += pair.Key() + " -> "
+	}
+	switch v := value.(type) {
+	case *Dictionary:
+		return fmt.Sprintf("%sDict( size:%d ) {", prefix, len(v.Value)), padding(depth) + "}"
+	case *Array:
+		return fmt.Sprintf("%sArray( size:%d ) [", prefix, len(v.Value)), padding(depth) + "]"
+	}
+	return prefix, ""
+}
+
+func isEmptyContainer(o ObjectType) bool {
+	switch v := o.(type) {
+	case *Dictionary:
+		return len(v.Value) == 0
+	case *Array:
+		return len(v.Value) == 0
+	}
+	return false
+}
+
+func (r *diffRenderer) node(n *DiffNode, depth int, leftSuffix string, rightSuffix string) {
+	switch n.Kind {
+	case DiffInserted:
+		r.write("+ ", n.render(false, depth, r.opts)+rightSuffix)
+		return
+	case DiffDeleted:
+		r.write("- ", n.render(true, depth, r.opts)+leftSuffix)
+		return
+	}
+	if n.Kind == DiffEqual {
+		// Numbers within tolerance may still render differently
+		r.pair(n.render(true, depth, r.opts)+leftSuffix, n.render(true, depth, r.opts)+rightSuffix)
+		return
+	}
+	if len(n.Children) == 0 || isEmptyContainer(n.Left) || isEmptyContainer(n.Right) {
+		r.pair(n.render(true, depth, r.opts)+leftSuffix, n.render(false, depth, r.opts)+rightSuffix)
+		return
+	}
+
+	leftOpen, leftClose := n.bounds(true, depth)
+	rightOpen, rightClose := n.bounds(false, depth)
+	r.pair(leftOpen, rightOpen)
+	r.children(n.Children, depth+1, ",")
+	r.pair(leftClose+leftSuffix, rightClose+rightSuffix)
+}
+
+// children renders the child nodes of a container, the separator follows
+// every item but the last one of each side.
+func (r *diffRenderer) children(children []*DiffNode, depth int, separator string) {
+	leftLast := -1
+	rightLast := -1
+	for i, child := range children {
+		if child.Kind != DiffInserted {
+			leftLast = i
+		}
+		if child.Kind != DiffDeleted {
+			rightLast = i
+		}
+	}
+	for i, child := range children {
+		leftSuffix := separator
+		rightSuffix := separator
+		if i >= leftLast {
+			leftSuffix = ""
+		}
+		if i >= rightLast {
+			rightSuffix = ""
+		}
+		r.node(child, depth, leftSuffix, rightSuffix)
+	}
+}
+
+// object renders the difference of two matched objects.
+func (r *diffRenderer) object(n *DiffNode) {
+	r.opts = n.options
+	r.pair(n.Left.(*Object).header(), n.Right.(*Object).header())
+	r.children(n.Children, 1, "")
+	r.write("= ", "}\n")
+}
+
+// String renders the difference between two objects line by line.
+func (n *DiffNode) String() string {
+	r := diffRenderer{}
+	r.object(n)
+	return r.buffer.String()
+}
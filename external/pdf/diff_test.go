@@ -0,0 +1,156 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"testing"
+)
+
+// parseObject parses a document holding a single object with the given body.
+func parseObject(t testing.TB, body string) *Object {
+	d := pdfgen.New()
+	n := d.Add(body)
+	return parseDocument(t, d).Objects[fmt.Sprintf("%d,0", n)]
+}
+
+func TestDiffObjectsChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		expected []Change
+	}{
+		{
+			name:  "equal",
+			left:  "<< /A 1 /B /Name >>",
+			right: "<< /B /Name /A 1 >>",
+		},
+		{
+			name:     "inserted key",
+			left:     "<< /A 1 >>",
+			right:    "<< /A 1 /B 2 >>",
+			expected: []Change{{Path: "/B", Kind: ChangeAdded, New: "2.000000"}},
+		},
+		{
+			name:     "deleted key",
+			left:     "<< /A 1 /B 2 >>",
+			right:    "<< /A 1 >>",
+			expected: []Change{{Path: "/B", Kind: ChangeRemoved, Old: "2.000000"}},
+		},
+		{
+			name:     "modified key",
+			left:     "<< /A 1 /B /Old >>",
+			right:    "<< /A 1 /B /New >>",
+			expected: []Change{{Path: "/B", Kind: ChangeModified, Old: "Old", New: "New"}},
+		},
+		{
+			name:     "nested key",
+			left:     "<< /Font << /F1 << /BaseFont /Helvetica >> >> >>",
+			right:    "<< /Font << /F1 << /BaseFont /Courier >> >> >>",
+			expected: []Change{{Path: "/Font/F1/BaseFont", Kind: ChangeModified, Old: "Helvetica", New: "Courier"}},
+		},
+		{
+			name:     "array element",
+			left:     "<< /Kids [ /A /B ] >>",
+			right:    "<< /Kids [ /A /B /C ] >>",
+			expected: []Change{{Path: "/Kids[2]", Kind: ChangeAdded, New: "C"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := DiffObjects(parseObject(t, test.left), parseObject(t, test.right), nil)
+			changes := diff.Changes()
+			if len(changes) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, changes)
+			}
+			for i := range changes {
+				if changes[i] != test.expected[i] {
+					t.Errorf("change %d: expected %v, got %v", i, test.expected[i], changes[i])
+				}
+			}
+			if kind := childrenKind(diff.Children); (kind == DiffEqual) != (len(test.expected) == 0) {
+				t.Errorf("unexpected kind %s", kind)
+			}
+		})
+	}
+}
+
+func TestDiffString(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		expected string
+	}{
+		{
+			name:  "equal",
+			left:  "<< /A 1 >>",
+			right: "<< /A 1 >>",
+			expected: "= \tDict( size:1 ) {\n" +
+				"= \t\tA -> 1.000000\n" +
+				"= \t}\n" +
+				"= }\n" +
+				"= \n",
+		},
+		{
+			name:  "changed",
+			left:  "<< /A 1 /B [ /Y /Z ] /C 3 >>",
+			right: "<< /A 1 /B [ /Y /Z /X ] /D 4 >>",
+			expected: "= \tDict( size:3 ) {\n" +
+				"= \t\tA -> 1.000000,\n" +
+				"- \t\tB -> Array( size:2 ) [\n" +
+				"+ \t\tB -> Array( size:3 ) [\n" +
+				"= \t\t\tY,\n" +
+				"- \t\t\tZ\n" +
+				"+ \t\t\tZ,\n" +
+				"+ \t\t\tX\n" +
+				"= \t\t],\n" +
+				"- \t\tC -> 3.000000\n" +
+				"+ \t\tD -> 4.000000\n" +
+				"= \t}\n" +
+				"= }\n" +
+				"= \n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			left, right := parseObject(t, test.left), parseObject(t, test.right)
+			got := DiffObjects(left, right, nil).String()
+			// The header depends on the normalizations switched on
+			expected := "= " + left.header() + "\n" + test.expected
+			if got != expected {
+				t.Errorf("expected\n%s\ngot\n%s", expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffArrayInsertionAtFront(t *testing.T) {
+	nodes := diffArrays(labels("B", "C"), labels("A", "B", "C"), nil)
+	expected := []struct {
+		kind  DiffKind
+		key   string
+		left  string
+		right string
+	}{
+		{DiffInserted, "[0]", "", "A"},
+		{DiffEqual, "[0]", "B", "B"},
+		{DiffEqual, "[1]", "C", "C"},
+	}
+	if len(nodes) != len(expected) {
+		t.Fatalf("expected %d nodes, got %d", len(expected), len(nodes))
+	}
+	value := func(o ObjectType) string {
+		if o == nil {
+			return ""
+		}
+		return o.String()
+	}
+	for i, node := range nodes {
+		e := expected[i]
+		if node.Kind != e.kind || node.Key != e.key || value(node.Left) != e.left || value(node.Right) != e.right {
+			t.Errorf("node %d: expected %s %s %q %q, got %s %s %q %q", i,
+				e.key, e.kind, e.left, e.right, node.Key, node.Kind, value(node.Left), value(node.Right))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DotOptions limit the reference graph written by Dot.
+type DotOptions struct {
+	// Root limits the graph to the objects reachable from this object, nil
+	// includes every object unless a depth is given, in which case the
+	// graph starts at the document catalog.
+	Root *Object
+	// Depth is the number of references followed from the root, zero
+	// follows all of them. References back up the page tree are not
+	// followed.
+	Depth int
+	// ClusterPages groups the objects used by a single page with the page.
+	ClusterPages bool
+}
+
+func dotNode(o *Object) string {
+	return fmt.Sprintf("o%d_%d", o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration)
+}
+
+func dotLabel(o *Object) string {
+	label := fmt.Sprintf("%d %d", o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration)
+	if d := o.Dictionary(); d != nil {
+		for _, key := range []string{"Type", "Subtype"} {
+			if name := resolveName(d.Get(key)); name != "" {
+				label += "\n/" + name
+			}
+		}
+	}
+	if _, s := o.Stream(); s != nil {
+		label += "\nstream"
+	}
+	return label
+}
+
+// lastKey returns the last dictionary key of an edge label.
+func lastKey(label string) string {
+	label = label[strings.LastIndex(label, "/")+1:]
+	if i := strings.Index(label, "["); i >= 0 {
+		label = label[:i]
+	}
+	return label
+}
+
+// reachable returns the indices of the objects reachable from the root within
+// the given depth.
+func (g *referenceGraph) reachable(root int, depth int) map[int]bool {
+	included := map[int]bool{root: true}
+	frontier := []int{root}
+	for level := 0; len(frontier) > 0 && (depth == 0 || level < depth); level++ {
+		next := make([]int, 0)
+		for _, i := range frontier {
+			for label, targets := range g.outgoing[i] {
+				if pageBackLinks[lastKey(label)] {
+					continue
+				}
+				for _, target := range targets {
+					if !included[target] {
+						included[target] = true
+						next = append(next, target)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+	return included
+}
+
+// Dot renders the reference graph of the document in the Graphviz DOT
+// language. Nodes are labelled with the object number, /Type and /Subtype,
+// edges with the path of the reference within its source.
+func (p *PDF) Dot(opts *DotOptions) string {
+	g := newReferenceGraph(p)
+	root := opts.Root
+	if root == nil && opts.Depth > 0 {
+		root = trailerObject(p, "Root")
+	}
+	included := make(map[int]bool)
+	if i, ok := g.index[root]; ok {
+		included = g.reachable(i, opts.Depth)
+	} else {
+		for i := range g.objects {
+			included[i] = true
+		}
+	}
+
+	clusters := make(map[int][]int)
+	clustered := make(map[int]bool)
+	if opts.ClusterPages {
+		for hash, pages := range p.ObjectPages() {
+			i, ok := g.index[p.Objects[hash]]
+			if ok && included[i] && len(pages) == 1 {
+				clusters[pages[0]] = append(clusters[pages[0]], i)
+				clustered[i] = true
+			}
+		}
+	}
+
+	buffer := strings.Builder{}
+	node := func(indent string, i int) {
+		buffer.WriteString(fmt.Sprintf("%s%s [label=%s];\n", indent, dotNode(g.objects[i]), strconv.Quote(dotLabel(g.objects[i]))))
+	}
+	buffer.WriteString("digraph pdf {\n\tnode [shape=box];\n")
+	pages := make([]int, 0, len(clusters))
+	for page := range clusters {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	for _, page := range pages {
+		sort.Ints(clusters[page])
+		buffer.WriteString(fmt.Sprintf("\tsubgraph cluster_page%d {\n\t\tlabel=\"Page %d\";\n", page, page))
+		for _, i := range clusters[page] {
+			node("\t\t", i)
+		}
+		buffer.WriteString("\t}\n")
+	}
+	for i := range g.objects {
+		if included[i] && !clustered[i] {
+			node("\t", i)
+		}
+	}
+	for i := range g.objects {
+		if !included[i] {
+			continue
+		}
+		labels := make([]string, 0, len(g.outgoing[i]))
+		for label := range g.outgoing[i] {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			for _, target := range g.outgoing[i][label] {
+				if included[target] {
+					buffer.WriteString(fmt.Sprintf("\t%s -> %s [label=%s];\n", dotNode(g.objects[i]), dotNode(g.objects[target]), strconv.Quote(label)))
+				}
+			}
+		}
+	}
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
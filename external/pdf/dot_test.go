@@ -0,0 +1,29 @@
+package pdf
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"strings"
+	"testing"
+)
+
+func TestDot(t *testing.T) {
+	doc := parseDocument(t, pdfgen.TextDocument([]string{"Hello"}, []string{"World"}))
+
+	dot := doc.Dot(&DotOptions{ClusterPages: true})
+	for _, expected := range []string{
+		"subgraph cluster_page2 {",
+		`o3_0 [label="3 0\n/Font\n/Type1"];`,
+		`o5_0 -> o3_0 [label="/Resources/Font/F1"];`,
+		`o5_0 -> o2_0 [label="/Parent"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("expected %q in\n%s", expected, dot)
+		}
+	}
+
+	// The catalog, the page tree and the pages
+	dot = doc.Dot(&DotOptions{Depth: 2})
+	if nodes := strings.Count(dot, "[label=\"") - strings.Count(dot, " -> "); nodes != 4 {
+		t.Errorf("expected 4 nodes, got %d in\n%s", nodes, dot)
+	}
+}
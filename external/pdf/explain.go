@@ -0,0 +1,265 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Explanation breaks the score of a pair of values down into the scores of
+// their children. The weight is the share of the parent score a child
+// accounts for, so the contributions of the children add up to the score of
+// their parent.
+type Explanation struct {
+	Path         string         `json:"path"`
+	Kind         string         `json:"kind"`
+	Score        float64        `json:"score"`
+	Weight       float64        `json:"weight"`
+	Contribution float64        `json:"contribution"`
+	Note         string         `json:"note,omitempty"`
+	Children     []*Explanation `json:"children,omitempty"`
+}
+
+// Explain scores two values like MatchTypes and explains the score.
+func Explain(first ObjectType, second ObjectType, opts *MatchOptions) *Explanation {
+	e := explain(first, second, opts, "")
+	e.Weight = 1
+	e.Contribution = e.Score
+	return e
+}
+
+func explain(first ObjectType, second ObjectType, opts *MatchOptions, path string) *Explanation {
+	e := &Explanation{Path: path, Kind: kindOf(first)}
+	if reflect.TypeOf(first) != reflect.TypeOf(second) {
+		e.Note = fmt.Sprintf("%s against %s", kindOf(first), kindOf(second))
+		return e
+	}
+	if scorer := opts.Scorers.forType(first); scorer != nil {
+		e.Note = "custom scorer"
+		e.Score = scorer.Score(first, second, opts)
+		return e
+	}
+	e.Score = defaultScore(first, second, opts)
+	e.explainChildren(first, second, opts)
+	return e
+}
+
+// explainChildren pairs the children of two values of the same type like
+// defaultScore and explains the score of every pair. A child of the same type
+// or key is the counterpart even when it scores zero, so the mismatch shows.
+func (e *Explanation) explainChildren(first ObjectType, second ObjectType, opts *MatchOptions) {
+	switch v1 := first.(type) {
+	case *Object:
+		v2 := second.(*Object)
+		if opts.MatchDepth && v1.Depth != v2.Depth {
+			e.note("different depth")
+			return
+		}
+		firstHasStream := false
+		secondHasStream := false
+		weight := 1 / math.Max(float64(len(v1.Children)), float64(len(v2.Children)))
+		for i, c1 := range v1.Children {
+			if _, ok := c1.(*Stream); ok {
+				firstHasStream = true
+			}
+			bestMatch := 0.0
+			var bestChild ObjectType
+			for _, c2 := range v2.Children {
+				if _, ok := c2.(*Stream); ok {
+					secondHasStream = true
+				}
+				score := MatchTypes(c1, c2, opts)
+				if score > bestMatch || bestChild == nil && reflect.TypeOf(c1) == reflect.TypeOf(c2) {
+					bestMatch = score
+					bestChild = c2
+				}
+				if score == 1 {
+					break
+				}
+			}
+			e.record(childLabel(c1, i), c1, bestChild, weight, opts)
+		}
+		if firstHasStream != secondHasStream {
+			e.note("only one side has a stream")
+		}
+	case *Dictionary:
+		v2 := second.(*Dictionary)
+		total := 0.0
+		for _, d := range []*Dictionary{v1, v2} {
+			sum := 0.0
+			for i := range d.Value {
+				sum += opts.Scorers.weight(&d.Value[i])
+			}
+			total = math.Max(total, sum)
+		}
+		marked := make(map[int]bool)
+		// Entries with the same key scoring zero are not marked by the
+		// scoring, they are only paired for the explanation
+		paired := make(map[int]bool)
+		for i := range v1.Value {
+			c1 := &v1.Value[i]
+			weight := opts.Scorers.weight(c1)
+			if weight == 0 {
+				e.ignore("/"+c1.K.String(), c1)
+				continue
+			}
+			bestMatch := 0.0
+			bestKey := -1
+			counterpart := -1
+			for key := range v2.Value {
+				c2 := &v2.Value[key]
+				if marked[key] || opts.Scorers.weight(c2) == 0 {
+					continue
+				}
+				score := MatchTypes(c1, c2, opts)
+				if score > 0 && score > bestMatch {
+					bestMatch = score
+					bestKey = key
+				}
+				if score == 0 && counterpart == -1 && !paired[key] && c1.Key() == c2.Key() {
+					counterpart = key
+				}
+				if score == 1 {
+					break
+				}
+			}
+			switch {
+			case bestKey != -1:
+				marked[bestKey] = true
+				e.record("/"+c1.K.String(), c1, &v2.Value[bestKey], weight/total, opts)
+			case counterpart != -1:
+				paired[counterpart] = true
+				e.record("/"+c1.K.String(), c1, &v2.Value[counterpart], weight/total, opts)
+			default:
+				e.record("/"+c1.K.String(), c1, nil, weight/total, opts)
+			}
+		}
+		for key := range v2.Value {
+			c2 := &v2.Value[key]
+			if weight := opts.Scorers.weight(c2); !marked[key] && !paired[key] && weight > 0 {
+				e.record("/"+c2.K.String(), nil, c2, weight/total, opts)
+			}
+		}
+	case *Array:
+		v2 := second.(*Array)
+		weight := 1 / math.Max(float64(len(v1.Value)), float64(len(v2.Value)))
+		for _, pair := range alignArrays(v1, v2, opts) {
+			switch {
+			case pair.Right == -1:
+				e.record(fmt.Sprintf("[%d]", pair.Left), v1.Value[pair.Left], nil, weight, opts)
+			case pair.Left == -1:
+				e.record(fmt.Sprintf("[%d]", pair.Right), nil, v2.Value[pair.Right], weight, opts)
+			default:
+				e.record(fmt.Sprintf("[%d]", pair.Left), v1.Value[pair.Left], v2.Value[pair.Right], weight, opts)
+				if pair.Left != pair.Right {
+					e.Children[len(e.Children)-1].note(fmt.Sprintf("matched with [%d]", pair.Right))
+				}
+			}
+		}
+	case *KeyValuePair:
+		v2 := second.(*KeyValuePair)
+		if v1.Key() != v2.Key() {
+			return
+		}
+		e.Kind = kindOf(v1.V)
+		if opts.Scorers.forKey(v1) != nil {
+			e.note("custom key scorer")
+			return
+		}
+		if e.Score == 1 {
+			return
+		}
+		value := explain(v1.V, v2.V, opts, e.Path)
+		e.Kind = value.Kind
+		e.Note = value.Note
+		e.Children = value.Children
+	}
+}
+
+// childLabel returns the path of a child of an object, like DiffObjects.
+func childLabel(child ObjectType, i int) string {
+	if _, ok := child.(*Stream); ok {
+		return "stream"
+	}
+	if i == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%d]", i)
+}
+
+// record explains a pair of children weighing the given share of the score.
+// A child without counterpart is recorded with a score of zero.
+func (e *Explanation) record(path string, first ObjectType, second ObjectType, weight float64, opts *MatchOptions) {
+	var child *Explanation
+	switch {
+	case second == nil:
+		child = &Explanation{Path: e.Path + path, Kind: kindOf(first), Note: "only left"}
+	case first == nil:
+		child = &Explanation{Path: e.Path + path, Kind: kindOf(second), Note: "only right"}
+	default:
+		child = explain(first, second, opts, e.Path+path)
+	}
+	child.Weight = weight
+	child.Contribution = child.Score * weight
+	e.Children = append(e.Children, child)
+}
+
+// ignore records a child that does not count towards the score.
+func (e *Explanation) ignore(path string, first ObjectType) {
+	e.Children = append(e.Children, &Explanation{Path: e.Path + path, Kind: kindOf(first), Note: "ignored"})
+}
+
+func (e *Explanation) note(note string) {
+	if e.Note != "" {
+		e.Note += ", "
+	}
+	e.Note += note
+}
+
+func (e *Explanation) write(buffer *strings.Builder, depth int) {
+	path := e.Path
+	if path == "" {
+		path = "."
+	}
+	buffer.WriteString(fmt.Sprintf("%s%s %s: %.1f%%", padding(depth), path, e.Kind, e.Score*100))
+	if depth > 0 {
+		buffer.WriteString(fmt.Sprintf(" x %.3f = %.1f%%", e.Weight, e.Contribution*100))
+	}
+	if e.Note != "" {
+		buffer.WriteString(" (" + e.Note + ")")
+	}
+	buffer.WriteString("\n")
+
+	// Children of perfect matches are perfect as well
+	if e.Score == 1 {
+		return
+	}
+	for _, child := range e.Children {
+		child.write(buffer, depth+1)
+	}
+}
+
+// String renders the explanation as a tree, children of perfectly matching
+// values are left out.
+func (e *Explanation) String() string {
+	buffer := strings.Builder{}
+	e.write(&buffer, 0)
+	return buffer.String()
+}
+
+// ExplainMatch explains the score of the objects of a match with the scorers
+// and tolerances of the comparison. Strategies that also weigh the linked
+// objects may have scored the match differently.
+func (c *Comparison) ExplainMatch(match *ObjectMatch) *Explanation {
+	o1 := c.LeftDocument.Objects[match.Left.Hash()]
+	o2 := c.RightDocument.Objects[match.Right.Hash()]
+	opts := MatchOptions{}
+	if c.options != nil {
+		opts = *c.options
+	}
+	// Objects of a different depth were paired by a stage ignoring the
+	// depth, for objects of the same depth it makes no difference
+	opts.MatchDepth = o1.Depth == o2.Depth
+	return Explain(o1, o2, &opts)
+}
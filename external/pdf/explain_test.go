@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	right := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}, []string{"Inserted page"}))
+
+	// The roots of the page trees
+	o1 := left.Catalog().Get("Pages").(*ObjectReference).Value
+	o2 := right.Catalog().Get("Pages").(*ObjectReference).Value
+	opts := &MatchOptions{}
+	e := Explain(o1, o2, opts)
+	if score := MatchTypes(o1, o2, opts); e.Score != score {
+		t.Fatalf("expected score %f, got %f", score, e.Score)
+	}
+
+	// The contributions of the children add up to the score of their parent
+	var check func(e *Explanation)
+	check = func(e *Explanation) {
+		if len(e.Children) == 0 {
+			return
+		}
+		sum := 0.0
+		for _, child := range e.Children {
+			sum += child.Contribution
+			check(child)
+		}
+		if math.Abs(sum-e.Score) > 1e-9 {
+			t.Errorf("%s: contributions add up to %f, expected %f", e.Path, sum, e.Score)
+		}
+	}
+	check(e)
+
+	found := false
+	var find func(e *Explanation)
+	find = func(e *Explanation) {
+		if e.Path == "/Kids[1]" && e.Note == "only right" {
+			found = true
+		}
+		for _, child := range e.Children {
+			find(child)
+		}
+	}
+	find(e)
+	if !found {
+		t.Errorf("expected the added page to be explained, got\n%s", e)
+	}
+}
+
+func TestExplainZeroScoringCounterparts(t *testing.T) {
+	d1 := pdfgen.New()
+	n1 := d1.AddStream("/Kind /Font", []byte("first stream"))
+	d2 := pdfgen.New()
+	n2 := d2.AddStream("/Kind [ 1 ]", []byte("other data"))
+	o1 := parseDocument(t, d1).Objects[fmt.Sprintf("%d,0", n1)]
+	o2 := parseDocument(t, d2).Objects[fmt.Sprintf("%d,0", n2)]
+
+	e := Explain(o1, o2, &MatchOptions{MatchStream: true})
+	var check func(e *Explanation)
+	check = func(e *Explanation) {
+		if e.Note == "only left" || e.Note == "only right" {
+			t.Errorf("%s: expected the counterpart to be explained, got %q", e.Path, e.Note)
+		}
+		for _, child := range e.Children {
+			check(child)
+		}
+	}
+	check(e)
+	if !strings.Contains(e.String(), "stream Stream: 0.0%") || !strings.Contains(e.String(), "/Kind Label: 0.0%") {
+		t.Errorf("expected the stream and /Kind to score zero, got\n%s", e)
+	}
+}
+
+func TestExplainMatchUsesComparisonOptions(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	right := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	for _, o := range right.Objects {
+		if box, ok := o.Dictionary().Get("MediaBox").(*Array); ok {
+			box.Value[2].(*Number).Value += 0.001
+		}
+	}
+	options := DefaultCompareOptions()
+	options.Rules = &Rules{Tolerances: map[string]Tolerance{"MediaBox": {Absolute: 0.01}}}
+	result, err := CompareDocuments(left, right, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range result.Matches {
+		if e := result.ExplainMatch(match); e.Score != match.Score {
+			t.Errorf("object %d: explained score %f, matched with %f\n%s", match.Left.ObjectNumber, e.Score, match.Score, e)
+		}
+	}
+}
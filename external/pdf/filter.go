@@ -0,0 +1,306 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Filters producing encoded image data are kept as is, their output is the
+// image file format itself.
+var imageFilters = map[string]bool{
+	"DCTDecode":      true,
+	"JPXDecode":      true,
+	"CCITTFaxDecode": true,
+	"JBIG2Decode":    true,
+}
+
+// Decode applies the filters listed in the stream dictionary and returns the
+// decoded data. A nil dictionary returns the raw data.
+func (s *Stream) Decode(dict *Dictionary) ([]byte, error) {
+	data := s.Value
+	if dict == nil {
+		return data, nil
+	}
+
+	filters := make([]string, 0)
+	params := make([]*Dictionary, 0)
+	if name := resolveName(dict.Get("Filter")); name != "" {
+		filters = append(filters, name)
+		params = append(params, resolveDictionary(dict.Get("DecodeParms")))
+	} else if arr := resolveArray(dict.Get("Filter")); arr != nil {
+		parms := resolveArray(dict.Get("DecodeParms"))
+		for i, f := range arr.Value {
+			filters = append(filters, resolveName(f))
+			if parms != nil && i < len(parms.Value) {
+				params = append(params, resolveDictionary(parms.Value[i]))
+			} else {
+				params = append(params, nil)
+			}
+		}
+	}
+
+	for i, filter := range filters {
+		if imageFilters[filter] {
+			return data, nil
+		}
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = decodeFlate(data)
+			if err == nil {
+				data, err = applyPredictor(data, params[i])
+			}
+		case "LZWDecode", "LZW":
+			earlyChange := true
+			if params[i] != nil {
+				if v, ok := resolveNumber(params[i].Get("EarlyChange")); ok && v == 0 {
+					earlyChange = false
+				}
+			}
+			data, err = decodeLZW(data, earlyChange)
+			if err == nil {
+				data, err = applyPredictor(data, params[i])
+			}
+		case "ASCIIHexDecode", "AHx":
+			data, err = decodeASCIIHex(data)
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		case "RunLengthDecode", "RL":
+			data, err = decodeRunLength(data)
+		default:
+			err = fmt.Errorf("unsupported filter: %s", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func decodeFlate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		// Some writers omit the zlib header
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer r.Close()
+	output, err := io.ReadAll(r)
+	if err != nil && len(output) == 0 {
+		return nil, err
+	}
+	// Truncated streams are common, keep whatever could be inflated
+	return output, nil
+}
+
+func applyPredictor(data []byte, params *Dictionary) ([]byte, error) {
+	if params == nil {
+		return data, nil
+	}
+	predictor, _ := resolveNumber(params.Get("Predictor"))
+	if predictor < 10 {
+		if predictor == 2 {
+			return nil, errors.New("unsupported predictor: TIFF")
+		}
+		return data, nil
+	}
+	colors := 1
+	if v, ok := resolveNumber(params.Get("Colors")); ok {
+		colors = int(v)
+	}
+	bpc := 8
+	if v, ok := resolveNumber(params.Get("BitsPerComponent")); ok {
+		bpc = int(v)
+	}
+	columns := 1
+	if v, ok := resolveNumber(params.Get("Columns")); ok {
+		columns = int(v)
+	}
+
+	bpp := (colors*bpc + 7) / 8
+	rowSize := (colors*bpc*columns + 7) / 8
+	output := make([]byte, 0, len(data))
+	previous := make([]byte, rowSize)
+	for offset := 0; offset+1 <= len(data); offset += rowSize + 1 {
+		end := offset + 1 + rowSize
+		if end > len(data) {
+			end = len(data)
+		}
+		kind := data[offset]
+		row := make([]byte, rowSize)
+		copy(row, data[offset+1:end])
+		for i := 0; i < rowSize; i++ {
+			var left, up, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = previous[i-bpp]
+			}
+			up = previous[i]
+			switch kind {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("invalid png predictor: %d", kind)
+			}
+		}
+		output = append(output, row...)
+		previous = row
+	}
+	return output, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa := abs(p - int(a))
+	pb := abs(p - int(b))
+	pc := abs(p - int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	} else if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	digits := make([]byte, 0, len(data))
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isWhitespace(c) {
+			continue
+		}
+		digits = append(digits, c)
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	output := make([]byte, len(digits)/2)
+	_, err := hex.Decode(output, digits)
+	return output, err
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	output := make([]byte, 4*len(data))
+	n, _, err := ascii85.Decode(output, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return output[:n], nil
+}
+
+func decodeRunLength(data []byte) ([]byte, error) {
+	output := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+		if n == 128 {
+			break
+		}
+		if n < 128 {
+			end := i + n + 1
+			if end > len(data) {
+				return nil, errors.New("truncated run length data")
+			}
+			output = append(output, data[i:end]...)
+			i = end
+		} else {
+			if i >= len(data) {
+				return nil, errors.New("truncated run length data")
+			}
+			for j := 0; j < 257-n; j++ {
+				output = append(output, data[i])
+			}
+			i++
+		}
+	}
+	return output, nil
+}
+
+func decodeLZW(data []byte, earlyChange bool) ([]byte, error) {
+	const clearCode = 256
+	const endCode = 257
+
+	offset := 0
+	if earlyChange {
+		offset = 1
+	}
+
+	table := make([][]byte, 258, 4096)
+	reset := func() {
+		table = table[:258]
+		for i := 0; i < 256; i++ {
+			table[i] = []byte{byte(i)}
+		}
+	}
+	reset()
+
+	output := make([]byte, 0, len(data)*2)
+	width := 9
+	var previous []byte
+	var buffer uint32
+	bits := 0
+	for _, c := range data {
+		buffer = buffer<<8 | uint32(c)
+		bits += 8
+		for bits >= width {
+			code := int(buffer>>(bits-width)) & (1<<width - 1)
+			bits -= width
+			switch {
+			case code == clearCode:
+				reset()
+				width = 9
+				previous = nil
+				continue
+			case code == endCode:
+				return output, nil
+			}
+			var entry []byte
+			if code < len(table) {
+				entry = table[code]
+			} else if code == len(table) && previous != nil {
+				entry = append(append([]byte{}, previous...), previous[0])
+			} else {
+				return nil, errors.New("invalid lzw code")
+			}
+			output = append(output, entry...)
+			if previous != nil && len(table) < 4096 {
+				table = append(table, append(append([]byte{}, previous...), entry[0]))
+			}
+			previous = entry
+			if len(table)+offset >= 1<<width && width < 12 {
+				width++
+			}
+		}
+	}
+	return output, nil
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
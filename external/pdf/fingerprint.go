@@ -0,0 +1,147 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fingerprint summarizes the structure of an object at increasing levels of
+// detail. Objects can only match perfectly when their contents are equal, and
+// objects of a different class are not worth scoring at all.
+type fingerprint struct {
+	// Kinds of the children
+	kinds string
+	// Kinds, /Type and /Subtype of the dictionary
+	class string
+	// Class and the normalized keys of the dictionary
+	shape string
+	// Shape and the hash of the decoded stream data, only set by the index
+	// since streams can not be decoded while parsing
+	content string
+	// Hash of the raw stream data
+	raw string
+	// Objects referred to
+	references string
+}
+
+// duplicateKey groups objects that could be duplicates of each other, which
+// requires equal references and raw stream data.
+func (f fingerprint) duplicateKey() string {
+	return f.shape + "|" + f.references + "|" + f.raw
+}
+
+func kindOf(o ObjectType) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", o), "*pdf.")
+}
+
+func fingerprintObject(o *Object) fingerprint {
+	kinds := make([]string, 0, len(o.Children))
+	types := make([]string, 0)
+	keys := make([]string, 0)
+	references := make([]string, 0)
+	f := fingerprint{}
+	for _, child := range o.Children {
+		kinds = append(kinds, kindOf(child))
+		switch v := child.(type) {
+		case *Dictionary:
+			for i := range v.Value {
+				pair := &v.Value[i]
+				keys = append(keys, pair.Key())
+				switch pair.K.String() {
+				case "Type", "Subtype":
+					types = append(types, pair.Key()+"="+pair.Value())
+				}
+			}
+		case *Stream:
+			f.raw = toHash(string(v.Value))
+		}
+		collectLinks(child, &references)
+	}
+	sort.Strings(kinds)
+	sort.Strings(keys)
+	sort.Strings(references)
+	f.kinds = strings.Join(kinds, ",")
+	sort.Strings(types)
+	f.class = strings.Join(append(kinds, types...), ",")
+	f.shape = f.class + "|" + strings.Join(keys, ",")
+	f.references = strings.Join(references, ",")
+	return f
+}
+
+func collectLinks(o ObjectType, output *[]string) {
+	switch v := o.(type) {
+	case *Dictionary:
+		for _, pair := range v.Value {
+			collectLinks(pair.V, output)
+		}
+	case *Array:
+		for _, child := range v.Value {
+			collectLinks(child, output)
+		}
+	case *ObjectReference:
+		*output = append(*output, v.Link.Hash())
+	}
+}
+
+// fingerprintIndex buckets objects by their fingerprints, so candidates for a
+// match are looked up instead of compared against every object.
+type fingerprintIndex struct {
+	fingerprints map[*Object]fingerprint
+	kinds        map[string][]*Object
+	classes      map[string][]*Object
+	contents     map[string][]*Object
+}
+
+func newFingerprintIndex(objects []*Object) *fingerprintIndex {
+	x := &fingerprintIndex{
+		fingerprints: make(map[*Object]fingerprint, len(objects)),
+		kinds:        make(map[string][]*Object),
+		classes:      make(map[string][]*Object),
+		contents:     make(map[string][]*Object),
+	}
+	for _, o := range objects {
+		f := fingerprintObject(o)
+		f.content = f.shape
+		for _, child := range o.Children {
+			if s, ok := child.(*Stream); ok {
+				f.content += "|" + s.Hash()
+			}
+		}
+		x.fingerprints[o] = f
+		x.kinds[f.kinds] = append(x.kinds[f.kinds], o)
+		x.classes[f.class] = append(x.classes[f.class], o)
+		x.contents[f.content] = append(x.contents[f.content], o)
+	}
+	return x
+}
+
+// candidates returns the indexed objects worth scoring against the object.
+func (x *fingerprintIndex) candidates(f fingerprint) []*Object {
+	return x.classes[f.class]
+}
+
+// fallbackCandidates returns the indexed objects with the same kinds of
+// children but another /Type or /Subtype, they are scored when no candidate
+// of the same class matches.
+func (x *fingerprintIndex) fallbackCandidates(f fingerprint) []*Object {
+	output := make([]*Object, 0)
+	for _, o := range x.kinds[f.kinds] {
+		if x.fingerprints[o].class != f.class {
+			output = append(output, o)
+		}
+	}
+	return output
+}
+
+// exactCandidates returns the indexed objects that could match the object
+// perfectly. Resolved objects are dropped from the front of the bucket, since
+// objects are mostly matched in order this keeps lookups short.
+func (x *fingerprintIndex) exactCandidates(f fingerprint, resolved func(*Object) bool) []*Object {
+	bucket := x.contents[f.content]
+	for len(bucket) > 0 && resolved(bucket[0]) {
+		bucket = bucket[1:]
+	}
+	x.contents[f.content] = bucket
+	return bucket
+}
@@ -0,0 +1,340 @@
+package pdf
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+type codespace struct {
+	low  []byte
+	high []byte
+}
+
+// cmap maps character codes of one or more bytes to unicode text, as
+// described by a ToUnicode stream.
+type cmap struct {
+	codespaces []codespace
+	mappings   map[int]map[uint32]string
+}
+
+func codeValue(b []byte) uint32 {
+	v := uint32(0)
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func utf16Text(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	if len(b)%2 == 1 {
+		units = append(units, uint16(b[len(b)-1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func (c *cmap) add(code []byte, text string) {
+	if c.mappings[len(code)] == nil {
+		c.mappings[len(code)] = make(map[uint32]string)
+	}
+	c.mappings[len(code)][codeValue(code)] = text
+}
+
+func parseCMap(data []byte) *cmap {
+	c := &cmap{mappings: make(map[int]map[uint32]string)}
+	for _, op := range ParseContent(data) {
+		switch op.Operator {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(op.Operands); i += 2 {
+				low, ok1 := op.Operands[i].(*Text)
+				high, ok2 := op.Operands[i+1].(*Text)
+				if ok1 && ok2 {
+					c.codespaces = append(c.codespaces, codespace{low: low.Bytes(), high: high.Bytes()})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(op.Operands); i += 2 {
+				src, ok1 := op.Operands[i].(*Text)
+				if !ok1 {
+					continue
+				}
+				switch dst := op.Operands[i+1].(type) {
+				case *Text:
+					c.add(src.Bytes(), utf16Text(dst.Bytes()))
+				case *Label:
+					if r, ok := glyphRune(dst.String()); ok {
+						c.add(src.Bytes(), string(r))
+					}
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(op.Operands); i += 3 {
+				low, ok1 := op.Operands[i].(*Text)
+				high, ok2 := op.Operands[i+1].(*Text)
+				if !ok1 || !ok2 {
+					continue
+				}
+				code := low.Bytes()
+				first := codeValue(code)
+				last := codeValue(high.Bytes())
+				if last < first || last-first > 0xffff {
+					continue
+				}
+				switch dst := op.Operands[i+2].(type) {
+				case *Text:
+					base := dst.Bytes()
+					for v := first; v <= last; v++ {
+						target := append([]byte{}, base...)
+						if len(target) > 0 {
+							// Only the last byte of the destination is incremented
+							target[len(target)-1] += byte(v - first)
+						}
+						c.add(codeBytes(v, len(code)), utf16Text(target))
+					}
+				case *Array:
+					for j, item := range dst.Value {
+						if t, ok := item.(*Text); ok && first+uint32(j) <= last {
+							c.add(codeBytes(first+uint32(j), len(code)), utf16Text(t.Bytes()))
+						}
+					}
+				}
+			}
+		}
+	}
+	return c
+}
+
+func codeBytes(v uint32, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return b
+}
+
+// codeLength returns the length of the character code starting the data, as
+// defined by the codespace ranges.
+func (c *cmap) codeLength(data []byte, fallback int) int {
+	for _, cs := range c.codespaces {
+		n := len(cs.low)
+		if n == 0 || n > len(data) || len(cs.high) != n {
+			continue
+		}
+		inside := true
+		for i := 0; i < n; i++ {
+			if data[i] < cs.low[i] || data[i] > cs.high[i] {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return n
+		}
+	}
+	return fallback
+}
+
+func (c *cmap) lookup(code []byte) (string, bool) {
+	m, ok := c.mappings[len(code)]
+	if !ok {
+		return "", false
+	}
+	text, ok := m[codeValue(code)]
+	return text, ok
+}
+
+// glyphRune returns the unicode value of a glyph name, following the naming
+// conventions of the Adobe Glyph List for names missing from glyphNames.
+func glyphRune(name string) (rune, bool) {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if strings.HasPrefix(name, "uni") && len(name) >= 7 {
+		if v, err := strconv.ParseUint(name[3:7], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	if strings.HasPrefix(name, "u") && len(name) >= 5 && len(name) <= 7 {
+		if v, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	return 0, false
+}
+
+type glyph struct {
+	text  string
+	width float64
+	space bool
+}
+
+type font struct {
+	composite    bool
+	toUnicode    *cmap
+	encoding     [256]rune
+	widths       map[uint32]float64
+	defaultWidth float64
+}
+
+func loadFont(dict *Dictionary) *font {
+	f := &font{
+		encoding:     standardEncoding,
+		widths:       make(map[uint32]float64),
+		defaultWidth: 500,
+	}
+	if dict == nil {
+		return f
+	}
+
+	if d, s := resolveStream(dict.Get("ToUnicode")); s != nil {
+		if data, err := s.Decode(d); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+
+	if resolveName(dict.Get("Subtype")) == "Type0" {
+		f.composite = true
+		f.defaultWidth = 1000
+		descendants := resolveArray(dict.Get("DescendantFonts"))
+		if descendants != nil && len(descendants.Value) > 0 {
+			f.loadCIDWidths(resolveDictionary(descendants.Value[0]))
+		}
+		return f
+	}
+
+	if resolveName(dict.Get("Subtype")) == "TrueType" {
+		f.encoding = winAnsiEncoding
+	}
+	encoding := dict.Get("Encoding")
+	if name := resolveName(encoding); name != "" {
+		f.applyBaseEncoding(name)
+	} else if d := resolveDictionary(encoding); d != nil {
+		if name := resolveName(d.Get("BaseEncoding")); name != "" {
+			f.applyBaseEncoding(name)
+		}
+		if differences := resolveArray(d.Get("Differences")); differences != nil {
+			code := 0
+			for _, item := range differences.Value {
+				switch v := Resolve(item).(type) {
+				case *Number:
+					code = int(v.Value)
+				case *Label:
+					if r, ok := glyphRune(v.String()); ok && code >= 0 && code < 256 {
+						f.encoding[code] = r
+					}
+					code++
+				}
+			}
+		}
+	}
+
+	firstChar, _ := resolveNumber(dict.Get("FirstChar"))
+	if widths := resolveArray(dict.Get("Widths")); widths != nil {
+		for i, w := range widths.Value {
+			if v, ok := resolveNumber(w); ok {
+				f.widths[uint32(int(firstChar)+i)] = v
+			}
+		}
+	}
+	if descriptor := resolveDictionary(dict.Get("FontDescriptor")); descriptor != nil {
+		if v, ok := resolveNumber(descriptor.Get("MissingWidth")); ok && v > 0 {
+			f.defaultWidth = v
+		}
+	}
+	return f
+}
+
+func (f *font) applyBaseEncoding(name string) {
+	switch name {
+	case "WinAnsiEncoding":
+		f.encoding = winAnsiEncoding
+	case "MacRomanEncoding":
+		f.encoding = macRomanEncoding
+	case "StandardEncoding":
+		f.encoding = standardEncoding
+	}
+}
+
+func (f *font) loadCIDWidths(dict *Dictionary) {
+	if dict == nil {
+		return
+	}
+	if v, ok := resolveNumber(dict.Get("DW")); ok {
+		f.defaultWidth = v
+	}
+	w := resolveArray(dict.Get("W"))
+	if w == nil {
+		return
+	}
+	// Entries are either "first [w1 w2 ...]" or "first last w"
+	for i := 0; i < len(w.Value); {
+		first, ok := resolveNumber(w.Value[i])
+		if !ok || i+1 >= len(w.Value) {
+			return
+		}
+		if arr := resolveArray(w.Value[i+1]); arr != nil {
+			for j, item := range arr.Value {
+				if v, ok := resolveNumber(item); ok {
+					f.widths[uint32(int(first)+j)] = v
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w.Value) {
+			return
+		}
+		last, _ := resolveNumber(w.Value[i+1])
+		width, _ := resolveNumber(w.Value[i+2])
+		for c := int(first); c <= int(last); c++ {
+			f.widths[uint32(c)] = width
+		}
+		i += 3
+	}
+}
+
+// decode splits a shown string into glyphs, mapping each character code to
+// unicode text through the ToUnicode map or the font encoding.
+func (f *font) decode(data []byte) []glyph {
+	glyphs := make([]glyph, 0, len(data))
+	fallback := 1
+	if f.composite {
+		fallback = 2
+	}
+	for i := 0; i < len(data); {
+		n := fallback
+		if f.toUnicode != nil {
+			n = f.toUnicode.codeLength(data[i:], fallback)
+		}
+		if i+n > len(data) {
+			n = len(data) - i
+		}
+		code := data[i : i+n]
+		i += n
+
+		g := glyph{width: f.defaultWidth, space: n == 1 && code[0] == ' '}
+		if w, ok := f.widths[codeValue(code)]; ok {
+			g.width = w
+		}
+		if f.toUnicode != nil {
+			if text, ok := f.toUnicode.lookup(code); ok {
+				g.text = text
+				glyphs = append(glyphs, g)
+				continue
+			}
+		}
+		if !f.composite && f.encoding[code[0]] != 0 {
+			g.text = string(f.encoding[code[0]])
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
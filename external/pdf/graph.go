@@ -0,0 +1,190 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// Number of rounds confidence is propagated through the references
+	floodingIterations = 10
+	// Propagation stops early once no score changes more than this
+	floodingEpsilon = 1e-4
+	// Weight of the propagated confidence against the local score
+	floodingWeight = 0.5
+)
+
+// referenceGraph holds the labelled references between the objects of a
+// document, the label is the path of the reference within its source, e.g.
+// /Resources/Font/F1. Objects are indexed in the order of SortedObjects.
+type referenceGraph struct {
+	objects  []*Object
+	index    map[*Object]int
+	outgoing []map[string][]int
+	incoming []map[string][]int
+	degree   []int
+}
+
+func newReferenceGraph(doc *PDF) *referenceGraph {
+	g := &referenceGraph{
+		objects: doc.SortedObjects(),
+		index:   make(map[*Object]int),
+	}
+	g.outgoing = make([]map[string][]int, len(g.objects))
+	g.incoming = make([]map[string][]int, len(g.objects))
+	g.degree = make([]int, len(g.objects))
+	for i, o := range g.objects {
+		g.index[o] = i
+		g.outgoing[i] = make(map[string][]int)
+		g.incoming[i] = make(map[string][]int)
+	}
+	for i, o := range g.objects {
+		for k, child := range o.Children {
+			label := ""
+			if k > 0 {
+				label = fmt.Sprintf("[%d]", k)
+			}
+			g.collect(i, child, label)
+		}
+	}
+	return g
+}
+
+func (g *referenceGraph) collect(source int, o ObjectType, label string) {
+	switch v := o.(type) {
+	case *Dictionary:
+		for _, pair := range v.Value {
+			g.collect(source, pair.V, label+"/"+pair.K.String())
+		}
+	case *Array:
+		for k, child := range v.Value {
+			g.collect(source, child, fmt.Sprintf("%s[%d]", label, k))
+		}
+	case *ObjectReference:
+		target, ok := g.index[v.Value]
+		if !ok {
+			return
+		}
+		g.outgoing[source][label] = append(g.outgoing[source][label], target)
+		g.incoming[target][label] = append(g.incoming[target][label], source)
+		g.degree[source]++
+		g.degree[target]++
+	}
+}
+
+// trailerObject returns the object the trailer entry of the document refers
+// to, if any.
+func trailerObject(doc *PDF, key string) *Object {
+	if doc.Trailer == nil {
+		return nil
+	}
+	if ref, ok := doc.Trailer.Get(key).(*ObjectReference); ok {
+		return ref.Value
+	}
+	return nil
+}
+
+// floodScores combines the local score of every pair of objects with the
+// confidence of the pairs they are linked to, in the spirit of similarity
+// flooding. Propagation starts from anchors that are known to correspond:
+// the document catalog, the document information and the aligned pages.
+func floodScores(m *matching, left *PDF, right *PDF, local [][]float64) ([][]float64, error) {
+	l := newReferenceGraph(left)
+	r := newReferenceGraph(right)
+
+	anchors := make(map[[2]int]bool)
+	anchor := func(o1 *Object, o2 *Object) {
+		i, ok1 := l.index[o1]
+		j, ok2 := r.index[o2]
+		if ok1 && ok2 {
+			anchors[[2]int{i, j}] = true
+		}
+	}
+	anchor(trailerObject(left, "Root"), trailerObject(right, "Root"))
+	anchor(trailerObject(left, "Info"), trailerObject(right, "Info"))
+	for _, pair := range alignPages(left.Pages(), right.Pages()) {
+		if pair[0] != nil && pair[1] != nil {
+			anchor(pair[0].Object, pair[1].Object)
+		}
+	}
+
+	combined := make([][]float64, len(l.objects))
+	flooded := make([][]float64, len(l.objects))
+	for i := range combined {
+		combined[i] = make([]float64, len(r.objects))
+		flooded[i] = make([]float64, len(r.objects))
+		for j := range combined[i] {
+			if anchors[[2]int{i, j}] {
+				flooded[i][j] = 1
+			}
+			combined[i][j] = (1-floodingWeight)*local[i][j] + floodingWeight*flooded[i][j]
+		}
+	}
+
+	// The best combined score among the counterparts of every neighbour of a
+	// reached through the same label
+	propagate := func(a map[string][]int, b map[string][]int) float64 {
+		sum := 0.0
+		for label, xs := range a {
+			ys, ok := b[label]
+			if !ok {
+				continue
+			}
+			for _, x := range xs {
+				best := 0.0
+				for _, y := range ys {
+					best = math.Max(best, combined[x][y])
+				}
+				sum += best
+			}
+		}
+		return sum
+	}
+
+	for iteration := 0; iteration < floodingIterations; iteration++ {
+		next := make([][]float64, len(l.objects))
+		changes := make([]float64, len(l.objects))
+		err := m.parallel(StageFlooding, len(next), func(i int) {
+			next[i] = make([]float64, len(r.objects))
+			for j := range next[i] {
+				if anchors[[2]int{i, j}] {
+					next[i][j] = 1
+					continue
+				}
+				degree := math.Max(float64(l.degree[i]), float64(r.degree[j]))
+				if local[i][j] == 0 || degree == 0 {
+					continue
+				}
+				sum := propagate(l.outgoing[i], r.outgoing[j]) + propagate(l.incoming[i], r.incoming[j])
+				next[i][j] = math.Min(sum/degree, 1)
+				changes[i] = math.Max(changes[i], math.Abs(next[i][j]-flooded[i][j]))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		change := 0.0
+		for _, c := range changes {
+			change = math.Max(change, c)
+		}
+		flooded = next
+		for i := range combined {
+			for j := range combined[i] {
+				combined[i][j] = (1-floodingWeight)*local[i][j] + floodingWeight*flooded[i][j]
+			}
+		}
+		if change < floodingEpsilon {
+			break
+		}
+	}
+
+	// Types that do not match stay apart whatever their neighbours
+	for i := range combined {
+		for j := range combined[i] {
+			if local[i][j] == 0 {
+				combined[i][j] = 0
+			}
+		}
+	}
+	return combined, nil
+}
@@ -0,0 +1,63 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"testing"
+)
+
+// fontDocument builds two pages using fonts with identical dictionaries that
+// only differ by their ToUnicode streams, swapping the order of the fonts
+// changes their object numbers.
+func fontDocument(swap bool) *pdfgen.Document {
+	d := pdfgen.New()
+	catalog := d.Reserve()
+	tree := d.Reserve()
+	first, second := d.Reserve(), d.Reserve()
+	firstMap, secondMap := d.Reserve(), d.Reserve()
+	if swap {
+		first, second = second, first
+		firstMap, secondMap = secondMap, firstMap
+	}
+	d.Set(firstMap, "<< /Length 9 >>\nstream\nfirst map\nendstream")
+	d.Set(secondMap, "<< /Length 14 >>\nstream\nthe second map\nendstream")
+	d.Set(first, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /ToUnicode %s >>", pdfgen.Ref(firstMap)))
+	d.Set(second, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /ToUnicode %s >>", pdfgen.Ref(secondMap)))
+	kids := ""
+	for i, font := range []int{first, second} {
+		content := d.AddStream("", []byte(fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Page %d) Tj ET", i+1)))
+		page := d.Add(fmt.Sprintf("<< /Type /Page /Parent %s /Resources << /Font << /F1 %s >> >> /Contents %s >>",
+			pdfgen.Ref(tree), pdfgen.Ref(font), pdfgen.Ref(content)))
+		kids += pdfgen.Ref(page) + " "
+	}
+	d.Set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", pdfgen.Ref(tree)))
+	d.Set(tree, fmt.Sprintf("<< /Type /Pages /Kids [ %s] /Count 2 >>", kids))
+	d.SetRoot(catalog)
+	return d
+}
+
+func TestGraphStrategy(t *testing.T) {
+	left := parseDocument(t, fontDocument(false))
+	right := parseDocument(t, fontDocument(true))
+	options := DefaultCompareOptions()
+	options.Strategy = StrategyGraph
+	result, err := CompareDocuments(left, right, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects 3 to 6 swap numbers, the rest keeps its number
+	expected := map[int]int{3: 4, 4: 3, 5: 6, 6: 5}
+	if len(result.Matches) != len(left.Objects) {
+		t.Fatalf("expected %d matches, got %d", len(left.Objects), len(result.Matches))
+	}
+	for _, match := range result.Matches {
+		want, ok := expected[match.Left.ObjectNumber]
+		if !ok {
+			want = match.Left.ObjectNumber
+		}
+		if match.Right.ObjectNumber != want {
+			t.Errorf("object %d matched with %d, expected %d", match.Left.ObjectNumber, match.Right.ObjectNumber, want)
+		}
+	}
+}
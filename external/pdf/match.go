@@ -1,6 +1,7 @@
 package pdf
 
 import (
+	"bytes"
 	"math"
 	"reflect"
 )
@@ -9,14 +10,36 @@ type MatchOptions struct {
 	MatchReferences bool
 	MatchDepth      bool
 	MatchStream     bool
+	// Scorers overrides the scoring of types and dictionary keys, nil uses
+	// the default scoring only
+	Scorers *Scorers
+	// Tolerance applies to every number without a tolerance for its key,
+	// numbers only equal when they are identical by default
+	Tolerance Tolerance
+	// Tolerances of numbers by the key of the dictionary entry they belong
+	// to, without the leading slash, e.g. "MediaBox". Numbers in arrays
+	// belong to the entry of the array.
+	Tolerances map[string]Tolerance
 }
 
+// MatchTypes scores the similarity of two values between 0 and 1, values of a
+// different type score 0.
 func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64 {
 
 	if reflect.TypeOf(first) != reflect.TypeOf(second) {
 		return 0.0
 	}
 
+	if scorer := opts.Scorers.forType(first); scorer != nil {
+		return scorer.Score(first, second, opts)
+	}
+	return defaultScore(first, second, opts)
+}
+
+// defaultScore is the default scoring of two values of the same type.
+// Children are scored with MatchTypes, so overrides apply at every level.
+func defaultScore(first ObjectType, second ObjectType, opts *MatchOptions) float64 {
+
 	switch first.(type) {
 	case *Object:
 		v1 := first.(*Object)
@@ -56,16 +79,28 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 	case *Dictionary:
 		v1 := first.(*Dictionary)
 		v2 := second.(*Dictionary)
-		if len(v1.Value) == 0 && len(v2.Value) == 0 {
+		total1 := 0.0
+		for i := range v1.Value {
+			total1 += opts.Scorers.weight(&v1.Value[i])
+		}
+		total2 := 0.0
+		for i := range v2.Value {
+			total2 += opts.Scorers.weight(&v2.Value[i])
+		}
+		if total1 == 0 && total2 == 0 {
 			return 1
 		}
 		acc := 0.0
 		marked := make(map[int]bool)
 		for _, c1 := range v1.Value {
+			weight := opts.Scorers.weight(&c1)
+			if weight == 0 {
+				continue
+			}
 			bestMatch := 0.0
 			bestKey := -1
 			for key, c2 := range v2.Value {
-				if marked[key] {
+				if marked[key] || opts.Scorers.weight(&c2) == 0 {
 					continue
 				}
 				score := MatchTypes(&c1, &c2, opts)
@@ -82,10 +117,10 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 			}
 			if bestKey != -1 {
 				marked[bestKey] = true
-				acc += bestMatch
+				acc += bestMatch * weight
 			}
 		}
-		return acc / math.Max(float64(len(v1.Value)), float64(len(v2.Value)))
+		return acc / math.Max(total1, total2)
 	case *Array:
 		v1 := first.(*Array)
 		v2 := second.(*Array)
@@ -93,23 +128,8 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 			return 1
 		}
 		acc := 0.0
-		i := 0
-		for _, c1 := range v1.Value {
-			bestMatch := 0.0
-			for j := i; j < len(v2.Value); j++ {
-				score := MatchTypes(c1, v2.Value[j], opts)
-				if score > 1 {
-					panic("score must be below or equal to 1")
-				}
-				if score > 0 && score > bestMatch {
-					bestMatch = score
-					i = j + 1
-				}
-				if score == 1 {
-					break
-				}
-			}
-			acc += bestMatch
+		for _, pair := range alignArrays(v1, v2, opts) {
+			acc += pair.Score
 		}
 		return acc / math.Max(float64(len(v1.Value)), float64(len(v2.Value)))
 	case *KeyValuePair:
@@ -118,6 +138,9 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 		if v1.Key() != v2.Key() {
 			return 0
 		}
+		if scorer := opts.Scorers.forKey(v1); scorer != nil {
+			return scorer.Score(v1.V, v2.V, opts)
+		}
 		_, ok1 := v1.V.(*Text)
 		_, ok2 := v2.V.(*Text)
 		if ok1 && ok2 && v1.Value() == v2.Value() {
@@ -143,9 +166,10 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 		}
 		return 0
 	case *Number:
-		v1 := first.(*Number).Value
+		n1 := first.(*Number)
+		v1 := n1.Value
 		v2 := second.(*Number).Value
-		if v1 == v2 {
+		if v1 == v2 || opts.tolerance(n1).equal(v1, v2) {
 			return 1.0
 		}
 		if v1 == 0 || v2 == 0 {
@@ -174,15 +198,17 @@ func MatchTypes(first ObjectType, second ObjectType, opts *MatchOptions) float64
 		}
 		return 0.5
 	case *Stream:
+		v1 := first.(*Stream)
+		v2 := second.(*Stream)
 		if opts.MatchStream {
-			v1 := first.(*Stream)
-			v2 := second.(*Stream)
-			if len(v1.Value) != len(v2.Value) {
+			// Duplicates are found while parsing, before the filters can be
+			// resolved, so the raw data is compared
+			if !bytes.Equal(v1.Value, v2.Value) {
 				return 0
 			}
 			return 1.0
 		}
-		return 1.0
+		return matchStreams(v1, v2)
 	case *ObjectReference:
 		if opts.MatchReferences {
 			v1 := first.(*ObjectReference)
@@ -0,0 +1,85 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelledObject is an object together with the path it was first reached by.
+type labelledObject struct {
+	path   string
+	object *Object
+}
+
+// collectLabelled appends the objects referenced by a value to the queue,
+// labelled with the path of the reference.
+func collectLabelled(o ObjectType, p string, visited map[*Object]bool, queue *[]labelledObject) {
+	switch v := o.(type) {
+	case *Object:
+		for i, child := range v.Children {
+			collectLabelled(child, p+childLabel(child, i), visited, queue)
+		}
+	case *Dictionary:
+		for _, pair := range v.Value {
+			if !pageBackLinks[pair.K.String()] {
+				collectLabelled(pair.V, p+"/"+pair.K.String(), visited, queue)
+			}
+		}
+	case *Array:
+		for i, child := range v.Value {
+			collectLabelled(child, fmt.Sprintf("%s[%d]", p, i), visited, queue)
+		}
+	case *ObjectReference:
+		if v.Value == nil || visited[v.Value] {
+			return
+		}
+		visited[v.Value] = true
+		*queue = append(*queue, labelledObject{p, v.Value})
+	}
+}
+
+// Normalized renders the document for line based diffs, e.g. as a git
+// textconv. Objects are labelled by the shortest path from the trailer that
+// reaches them instead of their number and follow in breadth first order, so
+// renumbering the objects leaves the output alone. Objects that cannot be
+// reached follow sorted by their rendering, the text of every page comes
+// last. Normalizations like HideIdentifiers are expected to be switched on
+// before parsing.
+func (p *PDF) Normalized() string {
+	buffer := strings.Builder{}
+	visited := make(map[*Object]bool)
+	queue := make([]labelledObject, 0)
+	if p.Trailer != nil {
+		for _, key := range []string{"Root", "Info"} {
+			collectLabelled(p.Trailer.Get(key), "/"+key, visited, &queue)
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		if p.ignores(queue[i].object) {
+			continue
+		}
+		buffer.WriteString(fmt.Sprintf("# %s\n", queue[i].path))
+		buffer.WriteString(queue[i].object.String())
+		collectLabelled(queue[i].object, queue[i].path, visited, &queue)
+	}
+
+	unreachable := make([]string, 0)
+	for _, o := range p.Objects {
+		if !visited[o] && !p.ignores(o) {
+			unreachable = append(unreachable, o.String())
+		}
+	}
+	sort.Strings(unreachable)
+	for _, s := range unreachable {
+		buffer.WriteString("# Unreachable\n")
+		buffer.WriteString(s)
+	}
+
+	for _, page := range ExtractText(p) {
+		buffer.WriteString(fmt.Sprintf("# Page %d text\n", page.Number))
+		buffer.WriteString(page.String())
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
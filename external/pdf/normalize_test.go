@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"strings"
+	"testing"
+)
+
+// catalogDocument builds a catalog with an info dictionary, adding the
+// objects in the given order to number them differently.
+func catalogDocument(infoFirst bool) *pdfgen.Document {
+	d := pdfgen.New()
+	var info, catalog int
+	if infoFirst {
+		info = d.Add("<< /Title (Report) >>")
+		catalog = d.Add("<< /Type /Catalog >>")
+	} else {
+		catalog = d.Add("<< /Type /Catalog >>")
+		info = d.Add("<< /Title (Report) >>")
+	}
+	d.Add("<< /Orphan true >>")
+	d.SetRoot(catalog)
+	d.SetInfo(info)
+	return d
+}
+
+func TestNormalized(t *testing.T) {
+	previous := HideIdentifiers
+	HideIdentifiers = true
+	defer func() {
+		HideIdentifiers = previous
+	}()
+
+	first := parseDocument(t, catalogDocument(false)).Normalized()
+	second := parseDocument(t, catalogDocument(true)).Normalized()
+	if first != second {
+		t.Errorf("expected renumbered documents to render the same, got\n%s\n%s", first, second)
+	}
+	for _, expected := range []string{"# /Root\n", "# /Info\n", "# Unreachable\n"} {
+		if !strings.Contains(first, expected) {
+			t.Errorf("expected %q in\n%s", expected, first)
+		}
+	}
+}
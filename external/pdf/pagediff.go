@@ -0,0 +1,282 @@
+package pdf
+
+import (
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	PageUnchanged        = "unchanged"
+	PageContentChanged   = "content changed"
+	PageResourcesChanged = "resources changed"
+	PageAdded            = "added"
+	PageRemoved          = "removed"
+)
+
+// PageComparison is the status of a page aligned between both documents. A
+// page number of zero means the page is missing from that document.
+type PageComparison struct {
+	LeftPage         int    `json:"leftPage"`
+	RightPage        int    `json:"rightPage"`
+	Status           string `json:"status"`
+	ContentChanged   bool   `json:"contentChanged"`
+	ResourcesChanged bool   `json:"resourcesChanged"`
+}
+
+// Keys that point back up the document structure, following them would make
+// every object reachable from every page.
+var pageBackLinks = map[string]bool{
+	"Parent": true,
+	"P":      true,
+	"Dest":   true,
+}
+
+func collectReachable(root ObjectType, visited map[*Object]bool, output *[]*Object) {
+	switch v := root.(type) {
+	case *Object:
+		for _, child := range v.Children {
+			collectReachable(child, visited, output)
+		}
+	case *Dictionary:
+		for _, pair := range v.Value {
+			if !pageBackLinks[pair.K.String()] {
+				collectReachable(pair.V, visited, output)
+			}
+		}
+	case *Array:
+		for _, child := range v.Value {
+			collectReachable(child, visited, output)
+		}
+	case *ObjectReference:
+		target := v.Value
+		if target == nil || visited[target] {
+			return
+		}
+		if d := target.Dictionary(); d != nil {
+			switch resolveName(d.Get("Type")) {
+			case "Page", "Pages", "Catalog":
+				return
+			}
+		}
+		visited[target] = true
+		*output = append(*output, target)
+		collectReachable(target, visited, output)
+	}
+}
+
+// Objects returns the objects used by the page, split into the objects of its
+// content streams and the resources, annotations and other objects it uses.
+func (p *Page) Objects() ([]*Object, []*Object) {
+	content := make([]*Object, 0)
+	resources := make([]*Object, 0)
+	dict := p.Object.Dictionary()
+	if dict == nil {
+		return content, resources
+	}
+	visited := map[*Object]bool{p.Object: true}
+	collectReachable(dict.Get("Contents"), visited, &content)
+	for _, pair := range dict.Value {
+		key := pair.K.String()
+		if key != "Contents" && !pageBackLinks[key] {
+			collectReachable(pair.V, visited, &resources)
+		}
+	}
+	if dict.Get("Resources") == nil && p.Resources != nil {
+		collectReachable(p.Resources, visited, &resources)
+	}
+	return content, resources
+}
+
+// unignored returns the resources of a page the rules did not leave out.
+func (p *PDF) unignored(page *Page) []*Object {
+	_, resources := page.Objects()
+	output := make([]*Object, 0, len(resources))
+	for _, o := range resources {
+		if !p.ignores(o) {
+			output = append(output, o)
+		}
+	}
+	return output
+}
+
+// ObjectPages maps the identifier hash of every object used by a page to the
+// numbers of the pages using it.
+func (p *PDF) ObjectPages() map[string][]int {
+	output := make(map[string][]int)
+	for _, page := range p.Pages() {
+		output[page.Object.Identifier.Hash()] = append(output[page.Object.Identifier.Hash()], page.Number)
+		content, resources := page.Objects()
+		for _, o := range append(content, resources...) {
+			output[o.Identifier.Hash()] = append(output[o.Identifier.Hash()], page.Number)
+		}
+	}
+	return output
+}
+
+// pageSimilarity returns the share of words both pages have in common, in
+// order.
+func pageSimilarity(left []string, right []string) float64 {
+	if len(left)+len(right) == 0 {
+		return 0
+	}
+	diffs, _ := diffTokens(left, right)
+	equal := 0
+	for _, d := range diffs {
+		if d.Type == diffmatchpatch.DiffEqual {
+			equal += len([]rune(d.Text))
+		}
+	}
+	return 2 * float64(equal) / float64(len(left)+len(right))
+}
+
+// alignRun pairs the removed and inserted pages in between equal pages by the
+// similarity of their text, so an inserted page does not shift the edited
+// pages after it. Pages without a similar counterpart are paired in order
+// with the other unpaired pages of the same gap.
+func alignRun(left []*Page, right []*Page) [][2]*Page {
+	words := func(pages []*Page) [][]string {
+		output := make([][]string, len(pages))
+		for i, page := range pages {
+			output[i] = ExtractPageText(page).Words()
+		}
+		return output
+	}
+	leftWords, rightWords := words(left), words(right)
+
+	pairs := make([][2]*Page, 0, len(left)+len(right))
+	var removed, inserted []*Page
+	flush := func() {
+		for len(removed) > 0 || len(inserted) > 0 {
+			pair := [2]*Page{}
+			if len(removed) > 0 {
+				pair[0], removed = removed[0], removed[1:]
+			}
+			if len(inserted) > 0 {
+				pair[1], inserted = inserted[0], inserted[1:]
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	alignments := alignSequences(len(left), len(right), func(i int, j int) float64 {
+		return pageSimilarity(leftWords[i], rightWords[j])
+	})
+	for _, a := range alignments {
+		switch {
+		case a.Right < 0:
+			removed = append(removed, left[a.Left])
+		case a.Left < 0:
+			inserted = append(inserted, right[a.Right])
+		default:
+			flush()
+			pairs = append(pairs, [2]*Page{left[a.Left], right[a.Right]})
+		}
+	}
+	flush()
+	return pairs
+}
+
+// alignPages pairs the pages of both documents by their content. Pages in
+// between equal pages are paired by the similarity of their text and the
+// remainder is reported as added or removed. Missing pages are returned as
+// nil.
+func alignPages(left []*Page, right []*Page) [][2]*Page {
+	signatures := func(pages []*Page) []string {
+		output := make([]string, len(pages))
+		for i, page := range pages {
+			output[i] = toHash(string(page.Contents()))
+		}
+		return output
+	}
+
+	pairs := make([][2]*Page, 0)
+	i, j := 0, 0
+	deleted, inserted := 0, 0
+	flush := func() {
+		pairs = append(pairs, alignRun(left[i:i+deleted], right[j:j+inserted])...)
+		i += deleted
+		j += inserted
+		deleted, inserted = 0, 0
+	}
+	diffs, _ := diffTokens(signatures(left), signatures(right))
+	for _, d := range diffs {
+		n := len([]rune(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			deleted += n
+		case diffmatchpatch.DiffInsert:
+			inserted += n
+		default:
+			flush()
+			for k := 0; k < n; k++ {
+				pairs = append(pairs, [2]*Page{left[i], right[j]})
+				i++
+				j++
+			}
+		}
+	}
+	flush()
+	return pairs
+}
+
+// pageResourcesChanged reports whether the page attributes or any of the
+// objects used by the page differ between the two pages.
+func (c *Comparison) pageResourcesChanged(left *Page, right *Page, matches map[string]*ObjectMatch) bool {
+	for _, change := range DiffObjects(left.Object, right.Object, c.options).Changes() {
+		if change.Path != "/Contents" {
+			return true
+		}
+	}
+
+	leftResources := c.LeftDocument.unignored(left)
+	rightResources := c.RightDocument.unignored(right)
+	if len(leftResources) != len(rightResources) {
+		return true
+	}
+	used := make(map[ObjectIdentifier]bool)
+	for _, o := range rightResources {
+		used[o.Identifier] = true
+	}
+	for _, o := range leftResources {
+		match, ok := matches[o.Identifier.Hash()]
+		if !ok || !used[match.Right] || len(match.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ComparePages aligns the pages of both documents and reports which of them
+// changed.
+func (c *Comparison) ComparePages() []*PageComparison {
+	matches := make(map[string]*ObjectMatch)
+	for _, match := range c.Matches {
+		matches[match.Left.Hash()] = match
+	}
+
+	output := make([]*PageComparison, 0)
+	for _, pair := range alignPages(c.LeftDocument.Pages(), c.RightDocument.Pages()) {
+		result := &PageComparison{}
+		switch {
+		case pair[0] == nil:
+			result.RightPage = pair[1].Number
+			result.Status = PageAdded
+		case pair[1] == nil:
+			result.LeftPage = pair[0].Number
+			result.Status = PageRemoved
+		default:
+			result.LeftPage = pair[0].Number
+			result.RightPage = pair[1].Number
+			result.ContentChanged = toHash(string(pair[0].Contents())) != toHash(string(pair[1].Contents()))
+			result.ResourcesChanged = c.pageResourcesChanged(pair[0], pair[1], matches)
+			switch {
+			case result.ContentChanged:
+				result.Status = PageContentChanged
+			case result.ResourcesChanged:
+				result.Status = PageResourcesChanged
+			default:
+				result.Status = PageUnchanged
+			}
+		}
+		output = append(output, result)
+	}
+	return output
+}
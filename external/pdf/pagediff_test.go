@@ -0,0 +1,52 @@
+package pdf
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"testing"
+)
+
+func TestAlignPages(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument(
+		[]string{"Cover"},
+		[]string{"Hello world"},
+		[]string{"Appendix"},
+	))
+	right := parseDocument(t, pdfgen.TextDocument(
+		[]string{"Cover"},
+		[]string{"Inserted page"},
+		[]string{"Hello brave world"},
+		[]string{"Appendix"},
+	))
+	expected := [][2]int{{1, 1}, {0, 2}, {2, 3}, {3, 4}}
+	pairs := alignPages(left.Pages(), right.Pages())
+	if len(pairs) != len(expected) {
+		t.Fatalf("expected %d pairs, got %d", len(expected), len(pairs))
+	}
+	for i, pair := range pairs {
+		got := [2]int{}
+		if pair[0] != nil {
+			got[0] = pair[0].Number
+		}
+		if pair[1] != nil {
+			got[1] = pair[1].Number
+		}
+		if got != expected[i] {
+			t.Errorf("pair %d: expected %v, got %v", i, expected[i], got)
+		}
+	}
+}
+
+func TestAlignPagesRewritten(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument(
+		[]string{"Cover"},
+		[]string{"Hello world"},
+	))
+	right := parseDocument(t, pdfgen.TextDocument(
+		[]string{"Cover"},
+		[]string{"Something else entirely"},
+	))
+	pairs := alignPages(left.Pages(), right.Pages())
+	if len(pairs) != 2 || pairs[1][0] == nil || pairs[1][1] == nil {
+		t.Fatalf("expected the rewritten page to stay paired, got %v", pairs)
+	}
+}
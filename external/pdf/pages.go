@@ -0,0 +1,209 @@
+package pdf
+
+import (
+	"sort"
+)
+
+// Get returns the value stored under the given key, without the leading slash.
+func (d *Dictionary) Get(key string) ObjectType {
+	for _, pair := range d.Value {
+		if pair.K.String() == key {
+			return pair.V
+		}
+	}
+	return nil
+}
+
+// Resolve follows an indirect reference and returns the direct value of the
+// referenced object. Other values are returned as is.
+func Resolve(o ObjectType) ObjectType {
+	ref, ok := o.(*ObjectReference)
+	if !ok {
+		return o
+	}
+	if ref.Value == nil || len(ref.Value.Children) == 0 {
+		return nil
+	}
+	return ref.Value.Children[0]
+}
+
+func resolveDictionary(o ObjectType) *Dictionary {
+	d, _ := Resolve(o).(*Dictionary)
+	return d
+}
+
+func resolveArray(o ObjectType) *Array {
+	a, _ := Resolve(o).(*Array)
+	return a
+}
+
+func resolveNumber(o ObjectType) (float64, bool) {
+	n, ok := Resolve(o).(*Number)
+	if !ok {
+		return 0, false
+	}
+	return n.Value, true
+}
+
+func resolveName(o ObjectType) string {
+	l, ok := Resolve(o).(*Label)
+	if !ok {
+		return ""
+	}
+	return l.String()
+}
+
+// Dictionary returns the dictionary of an object, if it has one.
+func (o *Object) Dictionary() *Dictionary {
+	for _, child := range o.Children {
+		if d, ok := child.(*Dictionary); ok {
+			return d
+		}
+	}
+	return nil
+}
+
+// Stream returns the stream of an object together with its dictionary.
+func (o *Object) Stream() (*Dictionary, *Stream) {
+	var dict *Dictionary
+	for _, child := range o.Children {
+		switch v := child.(type) {
+		case *Dictionary:
+			dict = v
+		case *Stream:
+			return dict, v
+		}
+	}
+	return nil, nil
+}
+
+func resolveStream(o ObjectType) (*Dictionary, *Stream) {
+	ref, ok := o.(*ObjectReference)
+	if !ok || ref.Value == nil {
+		return nil, nil
+	}
+	return ref.Value.Stream()
+}
+
+// SortedObjects returns the objects of the document ordered by object number.
+func (p *PDF) SortedObjects() []*Object {
+	return sortObjects(p.Objects)
+}
+
+func sortObjects(m map[string]*Object) []*Object {
+	objects := make([]*Object, 0, len(m))
+	for _, o := range m {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		a := objects[i].Identifier
+		b := objects[j].Identifier
+		if a.ObjectNumber != b.ObjectNumber {
+			return a.ObjectNumber < b.ObjectNumber
+		}
+		return a.ObjectGeneration < b.ObjectGeneration
+	})
+	return objects
+}
+
+// Catalog returns the document catalog. Documents without a parsed trailer
+// fall back to the first object with /Type /Catalog.
+func (p *PDF) Catalog() *Dictionary {
+	if p.Trailer != nil {
+		if root := resolveDictionary(p.Trailer.Get("Root")); root != nil {
+			return root
+		}
+	}
+	for _, o := range p.SortedObjects() {
+		d := o.Dictionary()
+		if d != nil && resolveName(d.Get("Type")) == "Catalog" {
+			return d
+		}
+	}
+	return nil
+}
+
+// Page is a leaf of the page tree with its inherited attributes applied.
+type Page struct {
+	Number    int
+	Object    *Object
+	Resources *Dictionary
+	MediaBox  *Array
+}
+
+// Pages walks the page tree and returns the pages in document order.
+func (p *PDF) Pages() []*Page {
+	catalog := p.Catalog()
+	if catalog == nil {
+		return nil
+	}
+	ref, ok := catalog.Get("Pages").(*ObjectReference)
+	if !ok {
+		return nil
+	}
+	pages := make([]*Page, 0)
+	visited := make(map[*Object]bool)
+	var walk func(ref *ObjectReference, resources *Dictionary, mediaBox *Array)
+	walk = func(ref *ObjectReference, resources *Dictionary, mediaBox *Array) {
+		if ref.Value == nil || visited[ref.Value] {
+			return
+		}
+		visited[ref.Value] = true
+		node := ref.Value.Dictionary()
+		if node == nil {
+			return
+		}
+		if r := resolveDictionary(node.Get("Resources")); r != nil {
+			resources = r
+		}
+		if m := resolveArray(node.Get("MediaBox")); m != nil {
+			mediaBox = m
+		}
+		kids := resolveArray(node.Get("Kids"))
+		if resolveName(node.Get("Type")) == "Page" || kids == nil {
+			pages = append(pages, &Page{
+				Number:    len(pages) + 1,
+				Object:    ref.Value,
+				Resources: resources,
+				MediaBox:  mediaBox,
+			})
+			return
+		}
+		for _, kid := range kids.Value {
+			if r, ok := kid.(*ObjectReference); ok {
+				walk(r, resources, mediaBox)
+			}
+		}
+	}
+	walk(ref, nil, nil)
+	return pages
+}
+
+// Contents returns the decoded content streams of the page, concatenated.
+func (p *Page) Contents() []byte {
+	dict := p.Object.Dictionary()
+	if dict == nil {
+		return nil
+	}
+	refs := make([]ObjectType, 0)
+	contents := dict.Get("Contents")
+	if arr := resolveArray(contents); arr != nil {
+		refs = append(refs, arr.Value...)
+	} else if contents != nil {
+		refs = append(refs, contents)
+	}
+	buffer := make([]byte, 0)
+	for _, ref := range refs {
+		d, s := resolveStream(ref)
+		if s == nil {
+			continue
+		}
+		data, err := s.Decode(d)
+		if err != nil {
+			continue
+		}
+		buffer = append(buffer, data...)
+		buffer = append(buffer, '\n')
+	}
+	return buffer
+}
@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Stages of a comparison reported to the progress callback
+const (
+	StageExact    = "exact"
+	StageClose    = "close"
+	StageDistant  = "distant"
+	StageScores   = "scores"
+	StageFlooding = "flooding"
+	StageAssign   = "assign"
+	StageDiff     = "diff"
+	StageFiles    = "files"
+)
+
+// Progress reports how many items of a stage of a comparison are done. Stages
+// that run in rounds start over at zero for every round.
+type Progress struct {
+	Stage string `json:"stage"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// matching carries the context, the size of the worker pool, the progress
+// callback, the scorers, the tolerances and the threshold of a comparison
+// through its stages.
+type matching struct {
+	ctx        context.Context
+	workers    int
+	progress   func(Progress)
+	scorers    *Scorers
+	tolerance  Tolerance
+	tolerances map[string]Tolerance
+	threshold  float64
+	mutex      sync.Mutex
+}
+
+func newMatching(ctx context.Context, options *CompareOptions) *matching {
+	workers := options.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	m := &matching{
+		ctx:       ctx,
+		workers:   workers,
+		progress:  options.Progress,
+		scorers:   options.Scorers,
+		tolerance: options.Tolerance,
+		threshold: options.Threshold,
+	}
+	m.tolerances = options.Tolerances
+	if options.Rules != nil && len(options.Rules.Tolerances) > 0 {
+		m.tolerances = make(map[string]Tolerance, len(options.Tolerances)+len(options.Rules.Tolerances))
+		for key, t := range options.Tolerances {
+			m.tolerances[key] = t
+		}
+		for key, t := range options.Rules.Tolerances {
+			m.tolerances[key] = t
+		}
+	}
+	return m
+}
+
+// options returns the match options of the comparison.
+func (m *matching) options(matchDepth bool) MatchOptions {
+	return MatchOptions{MatchDepth: matchDepth, Scorers: m.scorers, Tolerance: m.tolerance, Tolerances: m.tolerances}
+}
+
+// report calls the progress callback, one call at a time.
+func (m *matching) report(stage string, done int, total int) {
+	if m.progress == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.progress(Progress{Stage: stage, Done: done, Total: total})
+}
+
+// parallel calls fn for every index below n on the worker pool. It stops
+// handing out indices as soon as the context is done and returns its error.
+func (m *matching) parallel(stage string, n int, fn func(i int)) error {
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	workers := m.workers
+	if workers > n {
+		workers = n
+	}
+
+	next := int64(-1)
+	done := 0
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m.ctx.Err() == nil {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				fn(i)
+				if m.progress != nil {
+					m.mutex.Lock()
+					done++
+					m.progress(Progress{Stage: stage, Done: done, Total: n})
+					m.mutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return m.ctx.Err()
+}
@@ -1,10 +1,10 @@
 package pdf
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/aelbrecht/pdfdump/internal/token"
-	"log"
-	"os"
+	"io"
 	"strconv"
 	"strings"
 )
@@ -18,7 +18,19 @@ func NewParser(scanner *token.Scanner) *Parser {
 	}
 }
 
-func (p *Parser) Parse() {
+// Parse reads the objects and trailer of the document, malformed input is
+// returned as a *token.SyntaxError.
+func (p *Parser) Parse() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*token.SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+
 	hasTrailer := false
 	for p.scanner.HasToken() {
 
@@ -39,14 +51,11 @@ func (p *Parser) Parse() {
 
 		// Try to complete the parsing even when the trailer contains unknown structures
 		if hasTrailer {
-			fmt.Print("# parsing incomplete!\n\n")
 			break
 		}
 
 		// When no trailer was found, we must have crashed mid-way the PDF
-		fmt.Println("unknown prefix")
-		p.scanner.Dump()
-		os.Exit(1)
+		p.scanner.Fail("unknown prefix " + p.scanner.Peek())
 	}
 
 	visited := make(map[string]bool)
@@ -54,13 +63,22 @@ func (p *Parser) Parse() {
 	redirected := make(map[string]*Object)
 	dupes := 0
 	opts := MatchOptions{MatchReferences: true, MatchStream: true}
-	for k1, o1 := range p.objects {
+	objects := sortObjects(p.objects)
+	buckets := make(map[string][]*Object)
+	keys := make(map[*Object]string, len(objects))
+	for _, o := range objects {
+		keys[o] = fingerprintObject(o).duplicateKey()
+		buckets[keys[o]] = append(buckets[keys[o]], o)
+	}
+	for _, o1 := range objects {
+		k1 := o1.Identifier.Hash()
 		if visited[k1] {
 			continue
 		}
 		visited[k1] = true
 		uniques[k1] = o1
-		for k2, o2 := range p.objects {
+		for _, o2 := range buckets[keys[o1]] {
+			k2 := o2.Identifier.Hash()
 			if k1 == k2 {
 				continue
 			}
@@ -83,7 +101,7 @@ func (p *Parser) Parse() {
 		if o, ok := p.objects[ref.Link.Hash()]; !ok {
 			redirect, ok := redirected[ref.Link.Hash()]
 			if !ok {
-				panic("unresolved reference")
+				return &token.SyntaxError{Message: fmt.Sprintf("unresolved reference %d %d R", ref.Link.ObjectNumber, ref.Link.ObjectGeneration)}
 			}
 			ref.Link = redirect.Identifier
 			ref.Value = redirect
@@ -94,11 +112,21 @@ func (p *Parser) Parse() {
 		}
 	}
 
+	for _, ref := range p.trailerReferences {
+		if o, ok := p.objects[ref.Link.Hash()]; ok {
+			ref.Value = o
+		} else if redirect, ok := redirected[ref.Link.Hash()]; ok {
+			ref.Link = redirect.Identifier
+			ref.Value = redirect
+		}
+	}
+
 	for _, o := range p.objects {
 		if len(o.References) == 0 {
 			assignMinimalDepth(o, 0)
 		}
 	}
+	return nil
 }
 
 func assignMinimalDepth(root ObjectType, depth int) {
@@ -128,22 +156,25 @@ func assignMinimalDepth(root ObjectType, depth int) {
 }
 
 type Parser struct {
-	scanner    *token.Scanner
-	objects    map[string]*Object
-	version    string
-	references []*ObjectReference
+	scanner           *token.Scanner
+	objects           map[string]*Object
+	version           string
+	references        []*ObjectReference
+	trailer           *Dictionary
+	trailerReferences []*ObjectReference
 }
 
 func (p *Parser) PDF() *PDF {
 	return &PDF{
 		Version: p.version,
 		Objects: p.objects,
+		Trailer: p.trailer,
 	}
 }
 
-func (p *Parser) Dump(f *os.File) {
+func (p *Parser) Dump(w io.Writer) {
 	for _, child := range p.objects {
-		_, _ = f.WriteString(child.String())
+		_, _ = io.WriteString(w, child.String())
 	}
 }
 
@@ -156,10 +187,14 @@ func (p *Parser) ParseDict() (ObjectType, bool) {
 		if p.scanner.Pop(">>") {
 			return NewDictionary(dict), true
 		} else {
-			dict = append(dict, KeyValuePair{
+			pair := KeyValuePair{
 				K: p.ParseNext(),
 				V: p.ParseNext(),
-			})
+			}
+			if pair.K != nil {
+				assignKey(pair.V, pair.K.String())
+			}
+			dict = append(dict, pair)
 		}
 	}
 	panic("unreachable statement")
@@ -170,16 +205,26 @@ func (p *Parser) ParseStream() (ObjectType, bool) {
 	if !strings.HasPrefix(p.scanner.Peek(), "stream") {
 		return nil, false
 	}
-	buffer := make([]byte, 0)
+	delimiter := []byte{p.scanner.Delimiter()}
+	lines := make([][]byte, 0)
+	first := strings.TrimLeft(strings.TrimPrefix(p.scanner.NextLine(), "stream"), "\r")
+	if first != "" {
+		lines = append(lines, []byte(first))
+	}
 	for true {
-		t := p.scanner.Next()
-		buffer = append(buffer, []byte(t)...)
-		if t == "endstream" {
-			return NewStream(buffer), true
+		line := p.scanner.NextLine()
+		if strings.TrimSpace(line) == "endstream" {
+			break
 		}
+		if strings.HasSuffix(line, "endstream") {
+			lines = append(lines, []byte(strings.TrimSuffix(line, "endstream")))
+			break
+		}
+		lines = append(lines, []byte(line))
 	}
-	panic("unreachable statement")
-	return nil, false
+	buffer := bytes.Join(lines, delimiter)
+	buffer = bytes.TrimSuffix(buffer, []byte("\r"))
+	return NewStream(buffer), true
 }
 
 func (p *Parser) ParseNumber() (ObjectType, bool) {
@@ -263,11 +308,11 @@ func (p *Parser) ParseReference() (ObjectType, bool) {
 		return nil, false
 	}
 	objNum, err := strconv.Atoi(p.scanner.Next())
-	check(err)
+	p.check(err)
 	genNum, err := strconv.Atoi(p.scanner.Next())
-	check(err)
+	p.check(err)
 	if !p.scanner.Pop("R") {
-		panic("failed to parse indirect ref")
+		p.scanner.Fail("failed to parse indirect ref")
 	}
 	ref := NewReference(ObjectIdentifier{
 		ObjectNumber:     objNum,
@@ -283,7 +328,7 @@ func (p *Parser) ParseLabel() (ObjectType, bool) {
 	}
 	t := p.scanner.Next()
 	if t[0] != '/' {
-		panic("invalid label start")
+		p.scanner.Fail("invalid label start")
 	}
 	return NewLabel(t), true
 }
@@ -330,16 +375,13 @@ func (p *Parser) ParseNext() ObjectType {
 		return v
 	}
 
-	t := p.scanner.Next()
-	log.Printf("failed to parse: %s\n", t)
-	p.scanner.Dump()
-	os.Exit(1)
+	p.scanner.Fail("failed to parse: " + p.scanner.Peek())
 	return nil
 }
 
-func check(err error) {
+func (p *Parser) check(err error) {
 	if err != nil {
-		panic(err)
+		p.scanner.Fail(err.Error())
 	}
 }
 
@@ -348,9 +390,9 @@ func (p *Parser) ParseObject() (*Object, bool) {
 		return nil, false
 	}
 	objNum, err := strconv.Atoi(p.scanner.Next())
-	check(err)
+	p.check(err)
 	objGen, err := strconv.Atoi(p.scanner.Next())
-	check(err)
+	p.check(err)
 	p.scanner.Next() // obj
 	id := ObjectIdentifier{
 		ObjectNumber:     objNum,
@@ -362,6 +404,9 @@ func (p *Parser) ParseObject() (*Object, bool) {
 			return NewObject(id, children), true
 		}
 		child := p.ParseNext()
+		if s, ok := child.(*Stream); ok && len(children) > 0 {
+			s.dict, _ = children[len(children)-1].(*Dictionary)
+		}
 		children = append(children, child)
 	}
 	panic("unreachable statement")
@@ -377,6 +422,16 @@ func (p *Parser) ParseTrailer() bool {
 		if p.scanner.Pop("%%EOF") {
 			return true
 		}
+		if p.scanner.Pop("trailer") {
+			// Keep the trailer references apart, they should not count as incoming references
+			n := len(p.references)
+			if v, ok := p.ParseDict(); ok {
+				p.trailer = v.(*Dictionary)
+				p.trailerReferences = append(p.trailerReferences, p.references[n:]...)
+			}
+			p.references = p.references[:n]
+			continue
+		}
 		entries++
 		p.scanner.Next()
 	}
@@ -0,0 +1,29 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"github.com/aelbrecht/pdfdump/internal/token"
+	"testing"
+)
+
+func TestParseMalformed(t *testing.T) {
+	valid := pdfgen.TextDocument([]string{"Hello world"}).Bytes()
+	for name, data := range map[string][]byte{
+		"empty":     {},
+		"garbage":   []byte("this is not a document at all"),
+		"header":    []byte("%PDF-1.7\n"),
+		"truncated": valid[:len(valid)/2],
+		"unknown":   append(append([]byte{}, valid[:9]...), []byte("1 0 obj\n<< /A ) >>\nendobj\n")...),
+	} {
+		scanner, err := token.NewScanner(bytes.NewReader(data))
+		if err == nil {
+			err = NewParser(scanner).Parse()
+		}
+		var syntaxError *token.SyntaxError
+		if !errors.As(err, &syntaxError) {
+			t.Errorf("%s: expected a syntax error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package pdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maskedValue replaces the values of masked dictionary entries, so they are
+// equal on both sides but still show up in the output.
+const maskedValue = "(masked)"
+
+// Rules tune what counts as a difference on top of the built-in
+// normalizations. Ignored entries are removed from the documents and ignored
+// objects are left out of comparisons and renderings, masked values are
+// replaced by a placeholder.
+//
+// Keys are given without the leading slash. Paths are the paths of changes,
+// e.g. /Resources/Font/F1/BaseFont, and may contain the wildcards of
+// path.Match. Key patterns are regular expressions matched against the keys
+// of entries, mask patterns against the rendering of single values. Types are
+// the /Type of objects to leave out. Profiles are named sets of rules that are
+// added to the others when selected.
+type Rules struct {
+	IgnoreKeys     []string             `json:"ignoreKeys,omitempty" yaml:"ignoreKeys,omitempty"`
+	IgnorePaths    []string             `json:"ignorePaths,omitempty" yaml:"ignorePaths,omitempty"`
+	IgnoreTypes    []string             `json:"ignoreTypes,omitempty" yaml:"ignoreTypes,omitempty"`
+	IgnorePatterns []string             `json:"ignorePatterns,omitempty" yaml:"ignorePatterns,omitempty"`
+	MaskKeys       []string             `json:"maskKeys,omitempty" yaml:"maskKeys,omitempty"`
+	MaskPaths      []string             `json:"maskPaths,omitempty" yaml:"maskPaths,omitempty"`
+	MaskPatterns   []string             `json:"maskPatterns,omitempty" yaml:"maskPatterns,omitempty"`
+	Tolerances     map[string]Tolerance `json:"tolerances,omitempty" yaml:"tolerances,omitempty"`
+	Profiles       map[string]*Rules    `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	ignorePatterns []*regexp.Regexp
+	maskPatterns   []*regexp.Regexp
+}
+
+// LoadRules reads rules from a JSON file, or a YAML file when it ends in
+// .yaml or .yml, and adds the rules of the given profile, if any.
+func LoadRules(filePath string, profile string) (*Rules, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	rules := &Rules{}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, rules)
+	default:
+		err = json.Unmarshal(data, rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if profile != "" {
+		if err := rules.Select(profile); err != nil {
+			return nil, err
+		}
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return rules, nil
+}
+
+// Select adds the rules of a profile, the tolerances of the profile take
+// precedence.
+func (r *Rules) Select(profile string) error {
+	p, ok := r.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", profile)
+	}
+	r.IgnoreKeys = append(r.IgnoreKeys, p.IgnoreKeys...)
+	r.IgnorePaths = append(r.IgnorePaths, p.IgnorePaths...)
+	r.IgnoreTypes = append(r.IgnoreTypes, p.IgnoreTypes...)
+	r.IgnorePatterns = append(r.IgnorePatterns, p.IgnorePatterns...)
+	r.MaskKeys = append(r.MaskKeys, p.MaskKeys...)
+	r.MaskPaths = append(r.MaskPaths, p.MaskPaths...)
+	r.MaskPatterns = append(r.MaskPatterns, p.MaskPatterns...)
+	if len(p.Tolerances) > 0 && r.Tolerances == nil {
+		r.Tolerances = make(map[string]Tolerance)
+	}
+	for key, tolerance := range p.Tolerances {
+		r.Tolerances[key] = tolerance
+	}
+	r.ignorePatterns = nil
+	r.maskPatterns = nil
+	return nil
+}
+
+func (r *Rules) compile() error {
+	r.ignorePatterns = make([]*regexp.Regexp, 0, len(r.IgnorePatterns))
+	for _, pattern := range r.IgnorePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.ignorePatterns = append(r.ignorePatterns, re)
+	}
+	r.maskPatterns = make([]*regexp.Regexp, 0, len(r.MaskPatterns))
+	for _, pattern := range r.MaskPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.maskPatterns = append(r.maskPatterns, re)
+	}
+	for _, p := range append(append([]string{}, r.IgnorePaths...), r.MaskPaths...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("path %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func contains(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPath(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rules) ignored(key string, p string) bool {
+	if contains(r.IgnoreKeys, key) || matchesPath(r.IgnorePaths, p) {
+		return true
+	}
+	for _, re := range r.ignorePatterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rules) masked(key string, p string) bool {
+	return contains(r.MaskKeys, key) || matchesPath(r.MaskPaths, p)
+}
+
+// Apply removes the ignored entries from a parsed document and masks the
+// values, in place. Ignored objects stay in the document, so the references
+// to them resolve, but are marked to be left out.
+func (r *Rules) Apply(doc *PDF) error {
+	if r.ignorePatterns == nil || r.maskPatterns == nil {
+		if err := r.compile(); err != nil {
+			return err
+		}
+	}
+	for k, o := range doc.Objects {
+		if d := o.Dictionary(); d != nil && contains(r.IgnoreTypes, resolveName(d.Get("Type"))) {
+			if doc.ignored == nil {
+				doc.ignored = make(map[string]bool)
+			}
+			doc.ignored[k] = true
+			continue
+		}
+		for i, child := range o.Children {
+			o.Children[i] = r.apply(child, childLabel(child, i))
+		}
+	}
+	return nil
+}
+
+// apply returns the value at the given path with the rules applied.
+func (r *Rules) apply(o ObjectType, p string) ObjectType {
+	switch v := o.(type) {
+	case *Dictionary:
+		entries := v.Value[:0]
+		for _, pair := range v.Value {
+			key := pair.K.String()
+			entryPath := p + "/" + key
+			if r.ignored(key, entryPath) {
+				continue
+			}
+			if r.masked(key, entryPath) {
+				pair.V = NewText(maskedValue)
+			} else {
+				pair.V = r.apply(pair.V, entryPath)
+			}
+			entries = append(entries, pair)
+		}
+		v.Value = entries
+		return v
+	case *Array:
+		for i, child := range v.Value {
+			v.Value[i] = r.apply(child, fmt.Sprintf("%s[%d]", p, i))
+		}
+		return v
+	case *Stream, *ObjectReference:
+		return o
+	}
+	if matchesPath(r.MaskPaths, p) {
+		return NewText(maskedValue)
+	}
+	for _, re := range r.maskPatterns {
+		if re.MatchString(o.String()) {
+			return NewText(maskedValue)
+		}
+	}
+	return o
+}
+
+func (p *PDF) ignores(o *Object) bool {
+	return p.ignored[o.Identifier.Hash()]
+}
+
+// matchable returns the document without the ignored objects, as matched by
+// a comparison.
+func (p *PDF) matchable() *PDF {
+	if len(p.ignored) == 0 {
+		return p
+	}
+	objects := make(map[string]*Object, len(p.Objects))
+	for k, o := range p.Objects {
+		if !p.ignored[k] {
+			objects[k] = o
+		}
+	}
+	return &PDF{Version: p.Version, Objects: objects, Trailer: p.Trailer}
+}
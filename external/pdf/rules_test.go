@@ -0,0 +1,139 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	rules := `{
+		"ignoreKeys": ["Producer"],
+		"ignoreTypes": ["Metadata"],
+		"maskPaths": ["/Resources/Font/*/BaseFont"],
+		"profiles": {
+			"strict": {"tolerances": {"MediaBox": {"absolute": 0.5}}},
+			"lenient": {"ignorePatterns": ["^Creat"], "maskPatterns": ["^\"v[0-9.]+\"$"]}
+		}
+	}`
+	rulesPath := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(rulesPath, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := pdfgen.New()
+	info := d.Add("<< /Producer (Writer) /Creator (Tool) /Version (v1.2) >>")
+	metadata := d.Add("<< /Type /Metadata >>")
+	page := d.Add("<< /Type /Page /Resources << /Font << /F1 << /BaseFont /ABCDEF+Helvetica >> >> >> >>")
+	doc := parseDocument(t, d)
+
+	r, err := LoadRules(rulesPath, "lenient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	id := func(n int) string {
+		return fmt.Sprintf("%d,0", n)
+	}
+	if o, ok := doc.Objects[id(metadata)]; !ok || !doc.ignores(o) {
+		t.Error("expected the metadata object to be kept and ignored")
+	}
+	if strings.Contains(doc.String(), "Metadata") {
+		t.Error("expected the metadata object to be left out of the dump")
+	}
+	entries := doc.Objects[id(info)].Dictionary().Value
+	if len(entries) != 1 || entries[0].K.String() != "Version" || entries[0].V.(*Text).Value != maskedValue {
+		t.Errorf("expected only the masked version to remain, got %v", entries)
+	}
+	font := doc.Objects[id(page)].Dictionary().Get("Resources").(*Dictionary).Get("Font").(*Dictionary).Get("F1").(*Dictionary)
+	if v, ok := font.Get("BaseFont").(*Text); !ok || v.Value != maskedValue {
+		t.Errorf("expected the base font to be masked, got %v", font.Get("BaseFont"))
+	}
+
+	if r.Tolerances != nil {
+		t.Errorf("expected no tolerances without the strict profile")
+	}
+	if _, err := LoadRules(rulesPath, "unknown"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestLoadRulesYAML(t *testing.T) {
+	rules := `
+ignoreKeys: [Producer]
+maskPaths:
+  - /Resources/Font/*/BaseFont
+profiles:
+  strict:
+    tolerances:
+      MediaBox: {absolute: 0.5, round: true, decimals: 1}
+`
+	rulesPath := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesPath, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadRules(rulesPath, "strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.IgnoreKeys) != 1 || r.IgnoreKeys[0] != "Producer" || len(r.MaskPaths) != 1 {
+		t.Errorf("unexpected rules %+v", r)
+	}
+	if tolerance := r.Tolerances["MediaBox"]; tolerance != (Tolerance{Absolute: 0.5, Round: true, Decimals: 1}) {
+		t.Errorf("unexpected tolerance %+v", tolerance)
+	}
+
+	if err := os.WriteFile(rulesPath, []byte("ignoreKeys: {"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRules(rulesPath, ""); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestIgnoredTypesStayReferenced(t *testing.T) {
+	rules := &Rules{IgnoreTypes: []string{"Metadata"}}
+	document := func(metadata string) *PDF {
+		d := pdfgen.New()
+		catalog := d.Reserve()
+		tree := d.Reserve()
+		info := d.AddStream(fmt.Sprintf("/Type /Metadata /Subtype /XML /Revision %d", len(metadata)), []byte(metadata))
+		page := d.Add(fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [ 0 0 612 792 ] /Metadata %s >>", pdfgen.Ref(tree), pdfgen.Ref(info)))
+		d.Set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s /Metadata %s >>", pdfgen.Ref(tree), pdfgen.Ref(info)))
+		d.Set(tree, fmt.Sprintf("<< /Type /Pages /Kids [ %s ] /Count 1 >>", pdfgen.Ref(page)))
+		d.SetRoot(catalog)
+		doc := parseDocument(t, d)
+		if err := rules.Apply(doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	left := document("<x:xmpmeta>2020</x:xmpmeta>")
+	right := document("<x:xmpmeta>2024, edited</x:xmpmeta>")
+
+	options := DefaultCompareOptions()
+	options.Rules = rules
+	result, err := CompareDocumentsContext(context.Background(), left, right, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Equal() {
+		t.Errorf("expected the ignored metadata to make no difference, got\n%s", result)
+	}
+	catalog := result.LeftDocument.Catalog()
+	if ref, ok := catalog.Get("Metadata").(*ObjectReference); !ok || ref.Value == nil {
+		t.Errorf("expected the reference to the ignored metadata to resolve, got %v", catalog.Get("Metadata"))
+	}
+	for _, page := range result.Pages {
+		if page.Status != PageUnchanged {
+			t.Errorf("expected page %d to be unchanged, got %v", page.LeftPage, page.Status)
+		}
+	}
+}
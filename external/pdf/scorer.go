@@ -0,0 +1,85 @@
+package pdf
+
+import (
+	"reflect"
+)
+
+// Scorer scores the similarity of two values between 0 and 1. Children of the
+// values are expected to be scored with MatchTypes, so the overrides of the
+// options apply at every level.
+type Scorer interface {
+	Score(first ObjectType, second ObjectType, opts *MatchOptions) float64
+}
+
+// ScorerFunc adapts a function to the Scorer interface.
+type ScorerFunc func(first ObjectType, second ObjectType, opts *MatchOptions) float64
+
+func (f ScorerFunc) Score(first ObjectType, second ObjectType, opts *MatchOptions) float64 {
+	return f(first, second, opts)
+}
+
+// DefaultScorer is the built-in scoring of every type, overrides can fall back
+// to it.
+var DefaultScorer Scorer = ScorerFunc(defaultScore)
+
+// Scorers is a registry of scoring overrides. Types are scored by the scorer
+// registered for their type and dictionary entries by the scorer registered
+// for their key, anything else falls back to DefaultScorer. Weights change how
+// much a dictionary entry counts towards the score of its dictionary, a weight
+// of zero ignores the entry. A nil registry has no overrides.
+type Scorers struct {
+	types   map[reflect.Type]Scorer
+	keys    map[string]Scorer
+	weights map[string]float64
+}
+
+func NewScorers() *Scorers {
+	return &Scorers{
+		types:   make(map[reflect.Type]Scorer),
+		keys:    make(map[string]Scorer),
+		weights: make(map[string]float64),
+	}
+}
+
+// RegisterType scores values of the same type as the example with the scorer,
+// e.g. RegisterType(&Number{}, scorer). Both values are of that type.
+func (s *Scorers) RegisterType(example ObjectType, scorer Scorer) {
+	s.types[reflect.TypeOf(example)] = scorer
+}
+
+// RegisterKey scores the values of dictionary entries with the given key,
+// without the leading slash, with the scorer. The values may be of different
+// types.
+func (s *Scorers) RegisterKey(key string, scorer Scorer) {
+	s.keys[key] = scorer
+}
+
+// SetWeight sets the weight of dictionary entries with the given key, without
+// the leading slash. Entries weigh 1 by default.
+func (s *Scorers) SetWeight(key string, weight float64) {
+	s.weights[key] = weight
+}
+
+func (s *Scorers) forType(o ObjectType) Scorer {
+	if s == nil {
+		return nil
+	}
+	return s.types[reflect.TypeOf(o)]
+}
+
+func (s *Scorers) forKey(pair *KeyValuePair) Scorer {
+	if s == nil {
+		return nil
+	}
+	return s.keys[pair.K.String()]
+}
+
+func (s *Scorers) weight(pair *KeyValuePair) float64 {
+	if s == nil {
+		return 1
+	}
+	if weight, ok := s.weights[pair.K.String()]; ok {
+		return weight
+	}
+	return 1
+}
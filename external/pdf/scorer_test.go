@@ -0,0 +1,42 @@
+package pdf
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"testing"
+)
+
+func TestScorers(t *testing.T) {
+	d := pdfgen.New()
+	left := d.Add("<< /Title (Report) /Producer (Writer 1.0) /Pages 3 >>")
+	right := d.Add("<< /Title (Report) /Producer (Writer 2.0) /Pages 4 >>")
+	doc := parseDocument(t, d)
+	o1 := doc.Objects[(&ObjectIdentifier{ObjectNumber: left}).Hash()]
+	o2 := doc.Objects[(&ObjectIdentifier{ObjectNumber: right}).Hash()]
+
+	opts := MatchOptions{}
+	if score := MatchTypes(o1, o2, &opts); score != (1+0+0.75)/3 {
+		t.Errorf("unexpected default score %f", score)
+	}
+
+	opts.Scorers = NewScorers()
+	opts.Scorers.SetWeight("Producer", 0)
+	if score := MatchTypes(o1, o2, &opts); score != (1+0.75)/2 {
+		t.Errorf("unexpected score ignoring /Producer %f", score)
+	}
+
+	opts.Scorers.RegisterType(&Number{}, ScorerFunc(func(first ObjectType, second ObjectType, opts *MatchOptions) float64 {
+		return 1
+	}))
+	if score := MatchTypes(o1, o2, &opts); score != 1 {
+		t.Errorf("unexpected score with numbers always equal %f", score)
+	}
+
+	opts.Scorers = NewScorers()
+	opts.Scorers.SetWeight("Title", 2)
+	opts.Scorers.RegisterKey("Producer", ScorerFunc(func(first ObjectType, second ObjectType, opts *MatchOptions) float64 {
+		return 0.5
+	}))
+	if score := MatchTypes(o1, o2, &opts); score != (2+0.5+0.75)/4 {
+		t.Errorf("unexpected score with weighted /Title %f", score)
+	}
+}
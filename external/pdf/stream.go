@@ -0,0 +1,101 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+)
+
+// Size of the chunks binary data is compared by
+const streamChunkSize = 64
+
+// Data returns the decoded data of the stream, or the raw data when its
+// filters can not be applied. The data is decoded on first use, once the
+// references of the document are resolved.
+func (s *Stream) Data() []byte {
+	s.decode()
+	return s.data
+}
+
+// Hash returns the hash of the decoded data of the stream.
+func (s *Stream) Hash() string {
+	s.decode()
+	return s.hash
+}
+
+func (s *Stream) decode() {
+	s.decoded.Do(func() {
+		data, err := s.Decode(s.dict)
+		if err != nil {
+			data = s.Value
+		}
+		s.data = data
+		s.hash = toHash(string(data))
+		s.tokens = make(map[string]int)
+		for _, token := range streamTokens(data) {
+			s.tokens[token]++
+		}
+	})
+}
+
+// isText reports whether data looks like text, such as a content stream or a
+// CMap, by sampling its first bytes.
+func isText(data []byte) bool {
+	sample := data
+	if len(sample) > 1024 {
+		sample = sample[:1024]
+	}
+	printable := 0
+	for _, c := range sample {
+		if c == 0 {
+			return false
+		}
+		if c >= 0x20 && c < 0x7f || c == '\n' || c == '\r' || c == '\t' {
+			printable++
+		}
+	}
+	return printable*100 >= len(sample)*95
+}
+
+// streamTokens splits text into lines and binary data into chunks.
+func streamTokens(data []byte) []string {
+	tokens := make([]string, 0)
+	if isText(data) {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) > 0 {
+				tokens = append(tokens, string(line))
+			}
+		}
+		return tokens
+	}
+	for i := 0; i < len(data); i += streamChunkSize {
+		end := i + streamChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		tokens = append(tokens, string(data[i:end]))
+	}
+	return tokens
+}
+
+// matchStreams scores two streams by their decoded data. Equal data scores
+// one, otherwise the share of lines or chunks both have in common is scored
+// below one.
+func matchStreams(first *Stream, second *Stream) float64 {
+	if first.Hash() == second.Hash() {
+		return 1
+	}
+	total := 0
+	common := 0
+	for token, n := range first.tokens {
+		total += n
+		common += int(math.Min(float64(n), float64(second.tokens[token])))
+	}
+	for _, n := range second.tokens {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Min(2*float64(common)/float64(total), 0.99)
+}
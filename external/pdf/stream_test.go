@@ -0,0 +1,33 @@
+package pdf
+
+import (
+	"bytes"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"testing"
+)
+
+func TestMatchStreams(t *testing.T) {
+	d := pdfgen.New()
+	content := d.AddFlateStream("", []byte("BT\n/F1 12 Tf\n(Hello) Tj\nET"))
+	edited := d.AddFlateStream("", []byte("BT\n/F1 12 Tf\n(Hello world) Tj\nET"))
+	image := d.AddStream("/Subtype /Image", bytes.Repeat([]byte{0, 1, 2, 3}, 64))
+	replaced := d.AddStream("/Subtype /Image", bytes.Repeat([]byte{3, 2, 1, 0}, 64))
+	doc := parseDocument(t, d)
+
+	stream := func(n int) *Stream {
+		_, s := doc.Objects[(&ObjectIdentifier{ObjectNumber: n}).Hash()].Stream()
+		return s
+	}
+	if got := string(stream(content).Data()); got != "BT\n/F1 12 Tf\n(Hello) Tj\nET" {
+		t.Errorf("unexpected decoded data: %q", got)
+	}
+	if score := matchStreams(stream(content), stream(content)); score != 1 {
+		t.Errorf("expected equal streams to score 1, got %f", score)
+	}
+	if score := matchStreams(stream(content), stream(edited)); score != 0.75 {
+		t.Errorf("expected 3 of 4 lines in common to score 0.75, got %f", score)
+	}
+	if score := matchStreams(stream(image), stream(replaced)); score != 0 {
+		t.Errorf("expected a replaced image of the same size to score 0, got %f", score)
+	}
+}
@@ -0,0 +1,335 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TextSpan is a run of text shown by a single text operator.
+type TextSpan struct {
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	FontSize float64 `json:"fontSize"`
+	Text     string  `json:"text"`
+}
+
+// PageText holds the text shown on a page, in content stream order.
+type PageText struct {
+	Number int        `json:"page"`
+	Spans  []TextSpan `json:"spans"`
+}
+
+// String joins the spans of the page into plain text, starting a new line
+// whenever the baseline moves and inserting spaces between distant spans.
+func (p *PageText) String() string {
+	buffer := strings.Builder{}
+	for i, span := range p.Spans {
+		if i > 0 {
+			prev := p.Spans[i-1]
+			size := math.Max(prev.FontSize, 1)
+			if math.Abs(span.Y-prev.Y) > size/2 {
+				buffer.WriteString("\n")
+			} else if span.X-(prev.X+prev.Width) > size*0.15 &&
+				!strings.HasSuffix(prev.Text, " ") && !strings.HasPrefix(span.Text, " ") {
+				buffer.WriteString(" ")
+			}
+		}
+		buffer.WriteString(span.Text)
+	}
+	return buffer.String()
+}
+
+// Words returns the whitespace separated words of the page.
+func (p *PageText) Words() []string {
+	return strings.Fields(p.String())
+}
+
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translate(x, y float64) matrix {
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+type textState struct {
+	ctm         matrix
+	font        *font
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+	rise        float64
+}
+
+type textExtractor struct {
+	state      textState
+	stack      []textState
+	textMatrix matrix
+	lineMatrix matrix
+	fonts      map[*Dictionary]*font
+	spans      []TextSpan
+	positioned bool
+	depth      int
+}
+
+func numberOperands(op Operation) []float64 {
+	values := make([]float64, 0, len(op.Operands))
+	for _, o := range op.Operands {
+		if n, ok := o.(*Number); ok {
+			values = append(values, n.Value)
+		}
+	}
+	return values
+}
+
+func (e *textExtractor) font(resources *Dictionary, name string) *font {
+	var dict *Dictionary
+	if resources != nil {
+		if fonts := resolveDictionary(resources.Get("Font")); fonts != nil {
+			dict = resolveDictionary(fonts.Get(name))
+		}
+	}
+	if f, ok := e.fonts[dict]; ok {
+		return f
+	}
+	f := loadFont(dict)
+	e.fonts[dict] = f
+	return f
+}
+
+func (e *textExtractor) nextLine(tx, ty float64) {
+	e.lineMatrix = translate(tx, ty).multiply(e.lineMatrix)
+	e.textMatrix = e.lineMatrix
+}
+
+// show appends the glyphs of a string to the span, positioning the span at
+// its first glyph and advancing the text matrix past the shown glyphs.
+func (e *textExtractor) show(s *Text, span *TextSpan) {
+	f := e.state.font
+	if f == nil {
+		f = loadFont(nil)
+	}
+	for _, g := range f.decode(s.Bytes()) {
+		if !e.positioned {
+			trm := matrix{e.state.fontSize * e.state.scale, 0, 0, e.state.fontSize, 0, e.state.rise}.multiply(e.textMatrix).multiply(e.state.ctm)
+			span.X = trm[4]
+			span.Y = trm[5]
+			span.FontSize = math.Hypot(trm[2], trm[3])
+			e.positioned = true
+		}
+		span.Text += g.text
+		tx := g.width/1000*e.state.fontSize + e.state.charSpacing
+		if g.space {
+			tx += e.state.wordSpacing
+		}
+		e.advance(tx * e.state.scale)
+	}
+}
+
+func (e *textExtractor) advance(tx float64) {
+	e.textMatrix = translate(tx, 0).multiply(e.textMatrix)
+}
+
+func (e *textExtractor) emit(span TextSpan) {
+	e.positioned = false
+	if span.Text == "" {
+		return
+	}
+	end := e.textMatrix.multiply(e.state.ctm)
+	span.Width = end[4] - span.X
+	e.spans = append(e.spans, span)
+}
+
+func (e *textExtractor) run(data []byte, resources *Dictionary) {
+	for _, op := range ParseContent(data) {
+		args := numberOperands(op)
+		switch op.Operator {
+		case "q":
+			e.stack = append(e.stack, e.state)
+		case "Q":
+			if len(e.stack) > 0 {
+				e.state = e.stack[len(e.stack)-1]
+				e.stack = e.stack[:len(e.stack)-1]
+			}
+		case "cm":
+			if len(args) == 6 {
+				e.state.ctm = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}.multiply(e.state.ctm)
+			}
+		case "BT":
+			e.textMatrix = identity
+			e.lineMatrix = identity
+		case "Tf":
+			if len(op.Operands) == 2 {
+				if name, ok := op.Operands[0].(*Label); ok {
+					e.state.font = e.font(resources, name.String())
+				}
+				if size, ok := op.Operands[1].(*Number); ok {
+					e.state.fontSize = size.Value
+				}
+			}
+		case "Tc":
+			if len(args) == 1 {
+				e.state.charSpacing = args[0]
+			}
+		case "Tw":
+			if len(args) == 1 {
+				e.state.wordSpacing = args[0]
+			}
+		case "Tz":
+			if len(args) == 1 {
+				e.state.scale = args[0] / 100
+			}
+		case "TL":
+			if len(args) == 1 {
+				e.state.leading = args[0]
+			}
+		case "Ts":
+			if len(args) == 1 {
+				e.state.rise = args[0]
+			}
+		case "Td":
+			if len(args) == 2 {
+				e.nextLine(args[0], args[1])
+			}
+		case "TD":
+			if len(args) == 2 {
+				e.state.leading = -args[1]
+				e.nextLine(args[0], args[1])
+			}
+		case "Tm":
+			if len(args) == 6 {
+				e.lineMatrix = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+				e.textMatrix = e.lineMatrix
+			}
+		case "T*":
+			e.nextLine(0, -e.state.leading)
+		case "Tj", "'", "\"":
+			if op.Operator == "\"" && len(args) >= 2 {
+				e.state.wordSpacing = args[0]
+				e.state.charSpacing = args[1]
+			}
+			if op.Operator != "Tj" {
+				e.nextLine(0, -e.state.leading)
+			}
+			if len(op.Operands) == 0 {
+				continue
+			}
+			if s, ok := op.Operands[len(op.Operands)-1].(*Text); ok {
+				span := TextSpan{}
+				e.show(s, &span)
+				e.emit(span)
+			}
+		case "TJ":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			arr, ok := op.Operands[0].(*Array)
+			if !ok {
+				continue
+			}
+			span := TextSpan{}
+			for _, item := range arr.Value {
+				switch v := item.(type) {
+				case *Text:
+					e.show(v, &span)
+				case *Number:
+					// Large negative adjustments separate words
+					if v.Value < -200 && span.Text != "" && !strings.HasSuffix(span.Text, " ") {
+						span.Text += " "
+					}
+					e.advance(-v.Value / 1000 * e.state.fontSize * e.state.scale)
+				}
+			}
+			e.emit(span)
+		case "Do":
+			if len(op.Operands) == 1 && resources != nil {
+				name, ok := op.Operands[0].(*Label)
+				if !ok {
+					continue
+				}
+				xobjects := resolveDictionary(resources.Get("XObject"))
+				if xobjects == nil {
+					continue
+				}
+				e.form(xobjects.Get(name.String()), resources)
+			}
+		}
+	}
+}
+
+// form runs the content of a form XObject with its own resources.
+func (e *textExtractor) form(ref ObjectType, resources *Dictionary) {
+	dict, stream := resolveStream(ref)
+	if stream == nil || dict == nil || resolveName(dict.Get("Subtype")) != "Form" || e.depth > 8 {
+		return
+	}
+	data, err := stream.Decode(dict)
+	if err != nil {
+		return
+	}
+	if r := resolveDictionary(dict.Get("Resources")); r != nil {
+		resources = r
+	}
+	saved := e.state
+	savedText, savedLine := e.textMatrix, e.lineMatrix
+	if m := resolveArray(dict.Get("Matrix")); m != nil && len(m.Value) == 6 {
+		values := matrix{}
+		for i, v := range m.Value {
+			values[i], _ = resolveNumber(v)
+		}
+		e.state.ctm = values.multiply(e.state.ctm)
+	}
+	e.depth++
+	e.run(data, resources)
+	e.depth--
+	e.state = saved
+	e.textMatrix, e.lineMatrix = savedText, savedLine
+}
+
+// ExtractPageText returns the text shown on a single page.
+func ExtractPageText(page *Page) *PageText {
+	e := &textExtractor{
+		state: textState{ctm: identity, scale: 1},
+		fonts: make(map[*Dictionary]*font),
+		spans: make([]TextSpan, 0),
+	}
+	e.run(page.Contents(), page.Resources)
+	return &PageText{
+		Number: page.Number,
+		Spans:  e.spans,
+	}
+}
+
+// ExtractText returns the text of every page of the document.
+func ExtractText(doc *PDF) []*PageText {
+	pages := doc.Pages()
+	output := make([]*PageText, len(pages))
+	for i, page := range pages {
+		output[i] = ExtractPageText(page)
+	}
+	return output
+}
+
+// Positions lists the spans of the page with their position and font size.
+func (p *PageText) Positions() string {
+	buffer := strings.Builder{}
+	for _, span := range p.Spans {
+		buffer.WriteString(fmt.Sprintf("%.2f %.2f %.2f %s\n", span.X, span.Y, span.FontSize, span.Text))
+	}
+	return buffer.String()
+}
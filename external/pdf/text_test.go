@@ -0,0 +1,72 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"github.com/aelbrecht/pdfdump/internal/token"
+	"testing"
+)
+
+func parseDocument(t testing.TB, d *pdfgen.Document) *PDF {
+	t.Helper()
+	scanner, err := token.NewScanner(bytes.NewReader(d.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser(scanner)
+	if err := parser.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	return parser.PDF()
+}
+
+func TestExtractText(t *testing.T) {
+	doc := parseDocument(t, pdfgen.TextDocument(
+		[]string{"Invoice 2023-001", "Total (EUR) 12.50"},
+		[]string{"Second page"},
+	))
+	pages := ExtractText(doc)
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if got := pages[0].String(); got != "Invoice 2023-001\nTotal (EUR) 12.50" {
+		t.Errorf("unexpected text on page 1: %q", got)
+	}
+	if got := pages[1].String(); got != "Second page" {
+		t.Errorf("unexpected text on page 2: %q", got)
+	}
+	if span := pages[0].Spans[1]; span.X != 72 || span.Y != 706 || span.FontSize != 12 {
+		t.Errorf("unexpected position of second line: %+v", span)
+	}
+}
+
+func TestExtractTextEncodings(t *testing.T) {
+	d := pdfgen.New()
+	catalog := d.Reserve()
+	tree := d.Reserve()
+	simple := d.Add("<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman /Encoding << /Type /Encoding /BaseEncoding /MacRomanEncoding /Differences [ 1 /eacute /germandbls 65 /Adieresis ] >> >>")
+	cmap := d.AddStream("", []byte("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n"+
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n"+
+		"2 beginbfchar\n<0001> <0048>\n<0002> <0069>\nendbfchar\n"+
+		"1 beginbfrange\n<0010> <0012> <0061>\nendbfrange\n"+
+		"endcmap\nend\nend"))
+	descendant := d.Add("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /Arial /DW 500 >>")
+	composite := d.Add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /Arial /Encoding /Identity-H /DescendantFonts [ %s ] /ToUnicode %s >>",
+		pdfgen.Ref(descendant), pdfgen.Ref(cmap)))
+	content := d.AddStream("", []byte("BT /F1 10 Tf 50 700 Td (\\001\\002A\\216) Tj ET\n"+
+		"BT /F2 10 Tf 50 680 Td [ <00010002> -300 <001000110012> ] TJ ET"))
+	page := d.Add(fmt.Sprintf("<< /Type /Page /Parent %s /Resources << /Font << /F1 %s /F2 %s >> >> /Contents %s >>",
+		pdfgen.Ref(tree), pdfgen.Ref(simple), pdfgen.Ref(composite), pdfgen.Ref(content)))
+	d.Set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", pdfgen.Ref(tree)))
+	d.Set(tree, fmt.Sprintf("<< /Type /Pages /Kids [ %s ] /Count 1 >>", pdfgen.Ref(page)))
+	d.SetRoot(catalog)
+
+	pages := ExtractText(parseDocument(t, d))
+	if len(pages) != 1 {
+		t.Fatalf("expected 1 page, got %d", len(pages))
+	}
+	if got := pages[0].String(); got != "éßÄé\nHi abc" {
+		t.Errorf("unexpected text: %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package pdf
+
+import (
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"strings"
+)
+
+// TextComparison is the word level difference between the text of two
+// documents, page by page.
+type TextComparison struct {
+	LeftPath  string      `json:"left"`
+	RightPath string      `json:"right"`
+	Pages     []*PageDiff `json:"pages"`
+}
+
+// PageDiff holds the word level changes between a page of both documents. A
+// page number of zero means the page is missing from that document.
+type PageDiff struct {
+	LeftPage  int                   `json:"leftPage"`
+	RightPage int                   `json:"rightPage"`
+	Diffs     []diffmatchpatch.Diff `json:"diffs"`
+}
+
+// Equal reports whether the text of both pages is the same.
+func (d *PageDiff) Equal() bool {
+	for _, diff := range d.Diffs {
+		if diff.Type != diffmatchpatch.DiffEqual {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both documents have the same pages with the same text.
+func (c *TextComparison) Equal() bool {
+	for _, page := range c.Pages {
+		if page.LeftPage == 0 || page.RightPage == 0 || !page.Equal() {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords splits text into words followed by a space, line breaks are kept
+// as separate tokens so the layout survives the diff.
+func splitWords(text string) []string {
+	tokens := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		for _, word := range strings.Fields(line) {
+			tokens = append(tokens, word+" ")
+		}
+		tokens = append(tokens, "\n")
+	}
+	return tokens
+}
+
+// diffTokens returns the difference between two token sequences. Every
+// distinct token is mapped to a single rune in the text of the diffs, the
+// returned map recovers the tokens. The surrogate range is skipped since it
+// does not survive the conversion to a string.
+func diffTokens(left []string, right []string) ([]diffmatchpatch.Diff, map[rune]string) {
+	runes := make(map[string]rune)
+	tokens := make(map[rune]string)
+	encode := func(sequence []string) []rune {
+		output := make([]rune, len(sequence))
+		for i, token := range sequence {
+			r, ok := runes[token]
+			if !ok {
+				r = rune(len(runes) + 0x100)
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				runes[token] = r
+				tokens[r] = token
+			}
+			output[i] = r
+		}
+		return output
+	}
+	differ := diffmatchpatch.New()
+	return differ.DiffMainRunes(encode(left), encode(right), false), tokens
+}
+
+// DiffWords returns the word level difference between two texts.
+func DiffWords(left string, right string) []diffmatchpatch.Diff {
+	diffs, words := diffTokens(splitWords(left), splitWords(right))
+	for i := range diffs {
+		buffer := strings.Builder{}
+		for _, r := range diffs[i].Text {
+			buffer.WriteString(words[r])
+		}
+		diffs[i].Text = buffer.String()
+	}
+	return diffs
+}
+
+// CompareText extracts the text of both documents and diffs it page by page,
+// pages are aligned by their content to handle inserted and removed pages.
+func CompareText(leftPath string, rightPath string) (*TextComparison, error) {
+	left, err := parsePDF(leftPath)
+	if err != nil {
+		return nil, err
+	}
+	right, err := parsePDF(rightPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*PageDiff, 0)
+	for _, pair := range alignPages(left.Pages(), right.Pages()) {
+		diff := &PageDiff{}
+		leftText := ""
+		rightText := ""
+		if pair[0] != nil {
+			diff.LeftPage = pair[0].Number
+			leftText = ExtractPageText(pair[0]).String()
+		}
+		if pair[1] != nil {
+			diff.RightPage = pair[1].Number
+			rightText = ExtractPageText(pair[1]).String()
+		}
+		diff.Diffs = DiffWords(leftText, rightText)
+		pages = append(pages, diff)
+	}
+
+	return &TextComparison{
+		LeftPath:  leftPath,
+		RightPath: rightPath,
+		Pages:     pages,
+	}, nil
+}
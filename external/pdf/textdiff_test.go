@@ -0,0 +1,140 @@
+package pdf
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		expected []diffmatchpatch.Diff
+	}{
+		{
+			name:     "equal",
+			left:     "Hello world",
+			right:    "Hello world",
+			expected: []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffEqual, Text: "Hello world \n"}},
+		},
+		{
+			name:  "inserted word",
+			left:  "Hello world",
+			right: "Hello brave world",
+			expected: []diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "Hello "},
+				{Type: diffmatchpatch.DiffInsert, Text: "brave "},
+				{Type: diffmatchpatch.DiffEqual, Text: "world \n"},
+			},
+		},
+		{
+			name:  "deleted word",
+			left:  "Total 12.50 EUR",
+			right: "Total EUR",
+			expected: []diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "Total "},
+				{Type: diffmatchpatch.DiffDelete, Text: "12.50 "},
+				{Type: diffmatchpatch.DiffEqual, Text: "EUR \n"},
+			},
+		},
+		{
+			name:  "changed word",
+			left:  "Invoice 001",
+			right: "Invoice 002",
+			expected: []diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "Invoice "},
+				{Type: diffmatchpatch.DiffDelete, Text: "001 "},
+				{Type: diffmatchpatch.DiffInsert, Text: "002 "},
+				{Type: diffmatchpatch.DiffEqual, Text: "\n"},
+			},
+		},
+		{
+			name:  "inserted line",
+			left:  "first",
+			right: "first\nsecond",
+			expected: []diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "first \n"},
+				{Type: diffmatchpatch.DiffInsert, Text: "second \n"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs := DiffWords(test.left, test.right)
+			if len(diffs) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, diffs)
+			}
+			for i := range diffs {
+				if diffs[i] != test.expected[i] {
+					t.Errorf("diff %d: expected %v, got %v", i, test.expected[i], diffs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareTextPageCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     [][]string
+		right    [][]string
+		expected [][2]int
+		equal    []bool
+		same     bool
+	}{
+		{
+			name:     "same pages",
+			left:     [][]string{{"Cover"}, {"Hello world"}},
+			right:    [][]string{{"Cover"}, {"Hello world"}},
+			expected: [][2]int{{1, 1}, {2, 2}},
+			equal:    []bool{true, true},
+			same:     true,
+		},
+		{
+			name:     "inserted page",
+			left:     [][]string{{"Cover"}, {"Hello world"}},
+			right:    [][]string{{"Cover"}, {"Inserted page"}, {"Hello world"}},
+			expected: [][2]int{{1, 1}, {0, 2}, {2, 3}},
+			equal:    []bool{true, false, true},
+		},
+		{
+			name:     "removed page",
+			left:     [][]string{{"Cover"}, {"Hello world"}, {"Appendix"}},
+			right:    [][]string{{"Cover"}, {"Hello brave world"}},
+			expected: [][2]int{{1, 1}, {2, 2}, {3, 0}},
+			equal:    []bool{true, false, false},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			leftPath := filepath.Join(dir, "left.pdf")
+			rightPath := filepath.Join(dir, "right.pdf")
+			writeDocument(t, leftPath, pdfgen.TextDocument(test.left...))
+			writeDocument(t, rightPath, pdfgen.TextDocument(test.right...))
+
+			result, err := CompareText(leftPath, rightPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Pages) != len(test.expected) {
+				t.Fatalf("expected %d pages, got %d", len(test.expected), len(result.Pages))
+			}
+			for i, page := range result.Pages {
+				got := [2]int{page.LeftPage, page.RightPage}
+				if got != test.expected[i] {
+					t.Errorf("page %d: expected %v, got %v", i, test.expected[i], got)
+				}
+				if page.Equal() != test.equal[i] {
+					t.Errorf("page %d: expected equal %t, got %v", i, test.equal[i], page.Diffs)
+				}
+			}
+			if result.Equal() != test.same {
+				t.Errorf("expected document equality %t", test.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"math"
+)
+
+// Tolerance decides when two numbers are considered equal. Numbers are first
+// rounded to the given number of decimals when Round is set, then compared
+// with the absolute and relative tolerance.
+type Tolerance struct {
+	Absolute float64 `json:"absolute" yaml:"absolute"`
+	Relative float64 `json:"relative" yaml:"relative"`
+	Round    bool    `json:"round" yaml:"round"`
+	Decimals int     `json:"decimals" yaml:"decimals"`
+}
+
+func (t Tolerance) round(v float64) float64 {
+	if !t.Round {
+		return v
+	}
+	scale := math.Pow(10, float64(t.Decimals))
+	return math.Round(v*scale) / scale
+}
+
+func (t Tolerance) equal(a float64, b float64) bool {
+	a = t.round(a)
+	b = t.round(b)
+	difference := math.Abs(a - b)
+	if difference <= t.Absolute {
+		return true
+	}
+	return difference <= t.Relative*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// tolerance returns the tolerance of a number, the tolerance of its key or
+// else the default one. The options may be nil, numbers then only equal when
+// they are identical.
+func (o *MatchOptions) tolerance(f *Number) Tolerance {
+	if o == nil {
+		return Tolerance{}
+	}
+	if t, ok := o.Tolerances[f.key]; ok {
+		return t
+	}
+	return o.Tolerance
+}
+
+// withinTolerance reports whether both values are numbers that are equal
+// within the tolerance of the first.
+func withinTolerance(first ObjectType, second ObjectType, opts *MatchOptions) bool {
+	v1, ok1 := first.(*Number)
+	v2, ok2 := second.(*Number)
+	return ok1 && ok2 && opts.tolerance(v1).equal(v1.Value, v2.Value)
+}
+
+// assignKey ties the numbers of the value of a dictionary entry to its key.
+// Nested dictionaries are parsed first and keep the keys of their own entries.
+func assignKey(o ObjectType, key string) {
+	switch v := o.(type) {
+	case *Number:
+		if v.key == "" {
+			v.key = key
+		}
+	case *Array:
+		for _, child := range v.Value {
+			assignKey(child, key)
+		}
+	}
+}
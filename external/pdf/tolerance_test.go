@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"strings"
+	"testing"
+)
+
+func boxDocument(t *testing.T, box string, rotate string) *Object {
+	d := pdfgen.New()
+	n := d.Add("<< /MediaBox [ " + box + " ] /Rotate " + rotate + " >>")
+	return parseDocument(t, d).Objects[fmt.Sprintf("%d,0", n)]
+}
+
+func TestTolerance(t *testing.T) {
+	left := boxDocument(t, "0 0 612 792", "90")
+	right := boxDocument(t, "0.0001 0 612.0004 792", "90.2")
+	opts := &MatchOptions{}
+
+	if score := MatchTypes(left, right, opts); score == 1 {
+		t.Fatal("expected drifting numbers to differ without tolerance")
+	}
+	if changes := DiffObjects(left, right, opts).Changes(); len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %v", changes)
+	}
+
+	// Only the box is tolerated, the rotation still differs
+	opts = &MatchOptions{Tolerances: map[string]Tolerance{"MediaBox": {Absolute: 0.001}}}
+	changes := DiffObjects(left, right, opts).Changes()
+	if len(changes) != 1 || changes[0].Path != "/Rotate" {
+		t.Fatalf("expected only /Rotate to change, got %v", changes)
+	}
+	diff := DiffObjects(left, right, opts).String()
+	if strings.Contains(diff, "612.000400") || !strings.Contains(diff, "+ \t\tRotate -> 90.200000") {
+		t.Errorf("expected only the rotation to be marked, got\n%s", diff)
+	}
+
+	// Rounding applies to the rendering as well
+	opts = &MatchOptions{Tolerance: Tolerance{Round: true, Decimals: 0}}
+	if score := MatchTypes(left, right, opts); score != 1 {
+		t.Errorf("expected rounded numbers to match, got %f", score)
+	}
+	if changes := DiffObjects(left, right, opts).Changes(); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	if right.format(opts) != left.format(opts) {
+		t.Errorf("expected equal renderings, got\n%s\n%s", left.format(opts), right.format(opts))
+	}
+	if right.String() == left.String() {
+		t.Error("expected the plain rendering not to round")
+	}
+}
+
+func TestRuleTolerancesStayWithTheComparison(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	right := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	for _, o := range right.Objects {
+		if box, ok := o.Dictionary().Get("MediaBox").(*Array); ok {
+			box.Value[2].(*Number).Value += 0.001
+		}
+	}
+
+	options := DefaultCompareOptions()
+	options.Rules = &Rules{Tolerances: map[string]Tolerance{"MediaBox": {Absolute: 0.01}}}
+	result, err := CompareDocuments(left, right, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Equal() {
+		t.Errorf("expected the rule tolerance to hide the drift, got\n%s", result)
+	}
+
+	result, err = CompareDocuments(left, right, DefaultCompareOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Equal() {
+		t.Error("expected the drift to show without rules")
+	}
+}
+
+func TestComparisonRounding(t *testing.T) {
+	left := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	right := parseDocument(t, pdfgen.TextDocument([]string{"Hello world"}))
+	for _, o := range right.Objects {
+		if box, ok := o.Dictionary().Get("MediaBox").(*Array); ok {
+			box.Value[2].(*Number).Value += 0.3
+			o.Dictionary().Value = append(o.Dictionary().Value, KeyValuePair{K: NewLabel("/UserUnit"), V: NewNumber(1.0004)})
+		}
+	}
+
+	rounded := DefaultCompareOptions()
+	rounded.Tolerance = Tolerance{Round: true, Decimals: 0}
+	result, err := CompareDocuments(left, right, rounded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A comparison without tolerance in between must not change the first
+	plain, err := CompareDocuments(left, right, DefaultCompareOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := make([]Change, 0)
+	for _, match := range result.Matches {
+		changes = append(changes, match.Changes...)
+	}
+	if len(changes) != 1 || changes[0].Path != "/UserUnit" || changes[0].New != "1.000000" {
+		t.Errorf("expected only the added /UserUnit rounded to 1, got %v", changes)
+	}
+	if output := result.String(); strings.Contains(output, "612.300000") || strings.Contains(output, "1.000400") {
+		t.Errorf("expected the rendering to be rounded, got\n%s", output)
+	}
+	if output := plain.String(); !strings.Contains(output, "612.300000") {
+		t.Errorf("expected the rendering without tolerance to keep the drift, got\n%s", output)
+	}
+}
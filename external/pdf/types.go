@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var HideIdentifiers = false
@@ -21,6 +22,10 @@ var TrimFontPrefix = false
 type PDF struct {
 	Version string             `json:"version"`
 	Objects map[string]*Object `json:"objects"`
+	Trailer *Dictionary        `json:"trailer"`
+	// ignored holds the hashes of the objects left out by rules, they are
+	// kept so references to them still resolve
+	ignored map[string]bool
 }
 
 type ObjectType interface {
@@ -28,11 +33,13 @@ type ObjectType interface {
 }
 
 func (p *PDF) String() string {
-	buffer := ""
-	for _, child := range p.Objects {
-		buffer += child.String()
+	buffer := strings.Builder{}
+	for _, child := range p.SortedObjects() {
+		if !p.ignores(child) {
+			buffer.WriteString(child.String())
+		}
 	}
-	return buffer
+	return buffer.String()
 }
 
 type ObjectIdentifier struct {
@@ -55,31 +62,48 @@ type Object struct {
 	Depth      int                `json:"depth"`
 }
 
-var indent = 0
-
-func padding() string {
+func padding(depth int) string {
 	if NoIndents {
 		return ""
 	}
-	output := ""
-	for i := 0; i < indent; i++ {
-		output += "\t"
+	return strings.Repeat("\t", depth)
+}
+
+// format renders a value whose first line is at the given indentation depth,
+// numbers are rounded like the options tell, which may be nil.
+func format(o ObjectType, depth int, opts *MatchOptions) string {
+	switch v := o.(type) {
+	case *Dictionary:
+		return v.format(depth, opts)
+	case *Array:
+		return v.format(depth, opts)
+	case *KeyValuePair:
+		return v.format(depth, opts)
+	case *Number:
+		return v.format(opts)
+	default:
+		return o.String()
 	}
-	return output
 }
 
-func (o *Object) String() string {
-	indent++
-	items := make([]string, 0)
-	for _, child := range o.Children {
-		items = append(items, padding()+child.String())
-	}
-	indent--
+func (o *Object) header() string {
 	header := ""
 	if !HideIdentifiers {
 		header = fmt.Sprintf(" %s, refs:%d ", o.Identifier.String(), len(o.References))
 	}
-	return fmt.Sprintf("Object(%s) {\n%s\n}\n\n", header, strings.Join(items, "\n"))
+	return fmt.Sprintf("Object(%s) {", header)
+}
+
+func (o *Object) String() string {
+	return o.format(nil)
+}
+
+func (o *Object) format(opts *MatchOptions) string {
+	items := make([]string, 0)
+	for _, child := range o.Children {
+		items = append(items, padding(1)+format(child, 1, opts))
+	}
+	return fmt.Sprintf("%s\n%s\n}\n\n", o.header(), strings.Join(items, "\n"))
 }
 
 func NewObject(id ObjectIdentifier, children []ObjectType) *Object {
@@ -173,10 +197,17 @@ func NewNull() *Null {
 type Number struct {
 	Type  string  `json:"type"`
 	Value float64 `json:"value"`
+	// Key of the dictionary entry the number belongs to, which selects its
+	// tolerance
+	key string
 }
 
 func (f *Number) String() string {
-	return fmt.Sprintf("%f", f.Value)
+	return f.format(nil)
+}
+
+func (f *Number) format(opts *MatchOptions) string {
+	return fmt.Sprintf("%f", opts.tolerance(f).round(f.Value))
 }
 
 func NewNumber(f float64) *Number {
@@ -226,13 +257,20 @@ func NewLabel(t string) *Label {
 type Stream struct {
 	Type  string `json:"type"`
 	Value []byte `json:"value"`
+
+	// Dictionary of the object holding the stream, with the filters
+	dict    *Dictionary
+	decoded sync.Once
+	data    []byte
+	hash    string
+	tokens  map[string]int
 }
 
 func (s *Stream) String() string {
 	if HideStreamLength {
-		return fmt.Sprintf("Stream()")
+		return fmt.Sprintf("Stream( hash:%s )", s.Hash())
 	} else {
-		return fmt.Sprintf("Stream( size:%d )", len(s.Value))
+		return fmt.Sprintf("Stream( size:%d, hash:%s )", len(s.Value), s.Hash())
 	}
 }
 
@@ -263,10 +301,18 @@ var fontDictKeys = []string{
 var reRandomDictKeys = regexp.MustCompile("([A-Za-z]{1,4})([0-9]+)")
 
 func (k *KeyValuePair) String() string {
-	return fmt.Sprintf("%s -> %s", k.Key(), k.Value())
+	return k.format(0, nil)
+}
+
+func (k *KeyValuePair) format(depth int, opts *MatchOptions) string {
+	return fmt.Sprintf("%s -> %s", k.Key(), k.value(depth, opts))
 }
 
 func (k *KeyValuePair) Value() string {
+	return k.value(0, nil)
+}
+
+func (k *KeyValuePair) value(depth int, opts *MatchOptions) string {
 	key := k.K.String()
 	if HideVariableData {
 		for _, vk := range variableDictKeys {
@@ -287,7 +333,7 @@ func (k *KeyValuePair) Value() string {
 			}
 		}
 	}
-	return k.V.String()
+	return format(k.V, depth, opts)
 }
 
 func (k *KeyValuePair) Key() string {
@@ -327,16 +373,18 @@ type Dictionary struct {
 }
 
 func (d *Dictionary) String() string {
-	indent++
+	return d.format(0, nil)
+}
+
+func (d *Dictionary) format(depth int, opts *MatchOptions) string {
 	items := make([]string, 0)
-	for _, pair := range d.Value {
-		items = append(items, padding()+pair.String())
+	for i := range d.Value {
+		items = append(items, padding(depth+1)+d.Value[i].format(depth+1, opts))
 	}
-	indent--
 	if len(items) == 0 {
 		return "Dict( size:0 ) {}"
 	}
-	return fmt.Sprintf("Dict( size:%d ) {\n%s\n"+padding()+"}", len(items), strings.Join(items, ",\n"))
+	return fmt.Sprintf("Dict( size:%d ) {\n%s\n"+padding(depth)+"}", len(items), strings.Join(items, ",\n"))
 }
 
 func NewDictionary(dict []KeyValuePair) *Dictionary {
@@ -362,16 +410,18 @@ type Array struct {
 }
 
 func (a *Array) String() string {
-	indent++
+	return a.format(0, nil)
+}
+
+func (a *Array) format(depth int, opts *MatchOptions) string {
 	items := make([]string, 0)
 	for _, o := range a.Value {
-		items = append(items, padding()+o.String())
+		items = append(items, padding(depth+1)+format(o, depth+1, opts))
 	}
-	indent--
 	if len(items) == 0 {
 		return "Array( size:0 ) []"
 	}
-	return fmt.Sprintf("Array( size:%d ) [\n%s\n"+padding()+"]", len(items), strings.Join(items, ",\n"))
+	return fmt.Sprintf("Array( size:%d ) [\n%s\n"+padding(depth)+"]", len(items), strings.Join(items, ",\n"))
 }
 
 func NewArray(arr []ObjectType) *Array {
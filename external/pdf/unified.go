@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unifiedLine is a line of a rendered difference, kind is ' ', '-' or '+'.
+type unifiedLine struct {
+	kind byte
+	text string
+}
+
+// parseRendered reads the lines written by diffRenderer.
+func parseRendered(rendered string) []unifiedLine {
+	lines := make([]unifiedLine, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(rendered, "\n"), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		kind := line[0]
+		if kind == '=' {
+			kind = ' '
+		}
+		lines = append(lines, unifiedLine{kind, line[2:]})
+	}
+	return lines
+}
+
+// hunkRange formats the start and length of one side of a hunk, an empty
+// side starts at the line before it.
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+// unified writes lines as a unified diff with the given number of lines of
+// context around every change. Removed lines of a change precede the added
+// ones.
+func unified(leftName string, rightName string, lines []unifiedLine, context int) string {
+	changes := make([]int, 0)
+	for i, line := range lines {
+		if line.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// Line numbers of both sides before every line
+	leftNumbers := make([]int, len(lines)+1)
+	rightNumbers := make([]int, len(lines)+1)
+	leftNumbers[0], rightNumbers[0] = 1, 1
+	for i, line := range lines {
+		leftNumbers[i+1] = leftNumbers[i]
+		rightNumbers[i+1] = rightNumbers[i]
+		if line.kind != '+' {
+			leftNumbers[i+1]++
+		}
+		if line.kind != '-' {
+			rightNumbers[i+1]++
+		}
+	}
+
+	buffer := strings.Builder{}
+	buffer.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", leftName, rightName))
+	for k := 0; k < len(changes); {
+		// Changes closer than twice the context share a hunk
+		last := k
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context+1 {
+			last++
+		}
+		start := changes[k] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		buffer.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(leftNumbers[start], leftNumbers[end]-leftNumbers[start]),
+			hunkRange(rightNumbers[start], rightNumbers[end]-rightNumbers[start])))
+		for i := start; i < end; {
+			if lines[i].kind == ' ' {
+				buffer.WriteString(" " + lines[i].text + "\n")
+				i++
+				continue
+			}
+			j := i
+			for j < end && lines[j].kind != ' ' {
+				j++
+			}
+			for _, kind := range []byte{'-', '+'} {
+				for _, line := range lines[i:j] {
+					if line.kind == kind {
+						buffer.WriteString(string(kind) + line.text + "\n")
+					}
+				}
+			}
+			i = j
+		}
+		k = last + 1
+	}
+	return buffer.String()
+}
+
+// Unified renders the difference of the comparison as a unified diff of the
+// rendered objects, with the given number of lines of context.
+func (c *Comparison) Unified(context int) string {
+	return unified(c.LeftPath, c.RightPath, parseRendered(c.String()), context)
+}
@@ -0,0 +1,27 @@
+package pdf
+
+import (
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	rendered := "= a\n= b\n- c\n+ C\n= d\n= e\n= f\n= g\n= h\n+ i\n"
+	expected := "--- left\n+++ right\n" +
+		"@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n" +
+		"@@ -8 +8,2 @@\n h\n+i\n"
+	if got := unified("left", "right", parseRendered(rendered), 1); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// Interleaved lines are grouped, close changes share a hunk
+	rendered = "- a\n+ A\n- b\n+ B\n= c\n- d\n"
+	expected = "--- left\n+++ right\n" +
+		"@@ -1,4 +1,3 @@\n-a\n-b\n+A\n+B\n c\n-d\n"
+	if got := unified("left", "right", parseRendered(rendered), 1); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	if got := unified("left", "right", parseRendered("= a\n"), 3); got != "" {
+		t.Errorf("expected no output without changes, got\n%s", got)
+	}
+}
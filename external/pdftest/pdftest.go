@@ -0,0 +1,120 @@
+// Package pdftest compares generated documents against golden files in Go
+// tests. Golden files are rewritten instead when Update is set, or when the
+// test package defines the usual -update flag and the tests run with it:
+//
+//	var update = flag.Bool("update", false, "rewrite the golden files")
+//
+//	go test ./... -update
+package pdftest
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Update makes AssertEquivalent write the golden files instead of comparing
+// against them.
+var Update = false
+
+// updating reports whether golden files are written, either by Update or by
+// an -update flag of the test binary.
+func updating() bool {
+	if Update {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	v, ok := getter.Get().(bool)
+	return ok && v
+}
+
+// Changes beyond this number are summarized in failure messages
+const maxReportedChanges = 20
+
+// AssertEquivalent fails the test when the document differs from the golden
+// file. Documents are compared structurally with the options, nil uses the
+// default options, so ignore rules and tolerances decide what counts as a
+// difference. When updating the golden file is written instead.
+func AssertEquivalent(t testing.TB, got []byte, goldenPath string, opts *pdf.CompareOptions) {
+	t.Helper()
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("pdftest: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatalf("pdftest: %v", err)
+		}
+		t.Logf("pdftest: updated %s", goldenPath)
+		return
+	}
+	if _, err := os.Stat(goldenPath); err != nil {
+		t.Fatalf("pdftest: %v, run the test with -update to create it", err)
+	}
+
+	gotPath := filepath.Join(t.TempDir(), filepath.Base(goldenPath))
+	if err := os.WriteFile(gotPath, got, 0o644); err != nil {
+		t.Fatalf("pdftest: %v", err)
+	}
+	if opts == nil {
+		opts = pdf.DefaultCompareOptions()
+	}
+	result, err := pdf.CompareContext(context.Background(), goldenPath, gotPath, opts)
+	if err != nil {
+		t.Fatalf("pdftest: %v", err)
+	}
+	if result.Equal() {
+		return
+	}
+	t.Errorf("pdftest: document differs from %s, run the test with -update to accept it\n%s", goldenPath, describe(result))
+}
+
+// describe lists the differences of a comparison, one changed path per line.
+func describe(result *pdf.Comparison) string {
+	lines := make([]string, 0)
+	lines = append(lines, fmt.Sprintf("match rate %d%%, %d changed, %d removed and %d added objects",
+		int(math.Round(result.Summary.MatchRate*100)), result.Summary.Changed,
+		result.Summary.LeftUnmatched, result.Summary.RightUnmatched))
+	changes := 0
+	for _, match := range result.Matches {
+		if len(match.Changes) == 0 {
+			continue
+		}
+		if changes >= maxReportedChanges {
+			changes += len(match.Changes)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("object %d %d -> %d %d:", match.Left.ObjectNumber, match.Left.ObjectGeneration,
+			match.Right.ObjectNumber, match.Right.ObjectGeneration))
+		for _, change := range match.Changes {
+			changes++
+			if changes > maxReportedChanges {
+				continue
+			}
+			lines = append(lines, "\t"+change.String())
+		}
+	}
+	if changes > maxReportedChanges {
+		lines = append(lines, fmt.Sprintf("... and %d more changes", changes-maxReportedChanges))
+	}
+	for _, id := range result.LeftUnmatched {
+		lines = append(lines, fmt.Sprintf("removed object %d %d", id.ObjectNumber, id.ObjectGeneration))
+	}
+	for _, id := range result.RightUnmatched {
+		lines = append(lines, fmt.Sprintf("added object %d %d", id.ObjectNumber, id.ObjectGeneration))
+	}
+	return strings.Join(lines, "\n")
+}
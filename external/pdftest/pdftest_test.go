@@ -0,0 +1,83 @@
+package pdftest
+
+import (
+	"flag"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder collects the failures of an assertion instead of failing the test.
+type recorder struct {
+	*testing.T
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// fatal stops the assertion, the recorder recovers it.
+type fatal struct{}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	panic(fatal{})
+}
+
+// assert runs an assertion on the recorder until it ends or stops.
+func (r *recorder) assert(got []byte, goldenPath string) {
+	defer func() {
+		if v := recover(); v != nil && v != (fatal{}) {
+			panic(v)
+		}
+	}()
+	AssertEquivalent(r, got, goldenPath, nil)
+}
+
+// The usual golden file flag of a test package, pdftest picks it up
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestAssertEquivalent(t *testing.T) {
+	goldenPath := filepath.Join(t.TempDir(), "testdata", "hello.pdf")
+	hello := pdfgen.TextDocument([]string{"Hello world"}).Bytes()
+
+	*update = true
+	AssertEquivalent(t, hello, goldenPath, nil)
+	*update = false
+
+	r := &recorder{T: t}
+	r.assert(hello, goldenPath)
+	if len(r.errors) != 0 {
+		t.Fatalf("expected the golden file to match, got %v", r.errors)
+	}
+
+	r.assert(pdfgen.TextDocument([]string{"Hello world"}, []string{"Appendix"}).Bytes(), goldenPath)
+	if len(r.errors) != 1 {
+		t.Fatalf("expected one failure, got %v", r.errors)
+	}
+	for _, expected := range []string{"differs from " + goldenPath, "/Count: 1.000000 -> 2.000000", "added object"} {
+		if !strings.Contains(r.errors[0], expected) {
+			t.Errorf("expected %q in the failure message\n%s", expected, r.errors[0])
+		}
+	}
+}
+
+func TestAssertEquivalentUpdate(t *testing.T) {
+	goldenPath := filepath.Join(t.TempDir(), "hello.pdf")
+	Update = true
+	AssertEquivalent(t, pdfgen.TextDocument([]string{"Hello world"}).Bytes(), goldenPath, nil)
+	Update = false
+	if _, err := os.Stat(goldenPath); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{T: t}
+	r.assert([]byte("not a document"), goldenPath)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "could not read header") {
+		t.Errorf("expected a parse error, got %v", r.errors)
+	}
+}
@@ -3,3 +3,5 @@ module github.com/aelbrecht/pdfdump
 go 1.19
 
 require github.com/sergi/go-diff v1.3.1
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func isDir(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && info.IsDir()
+}
+
+func printFiles(result *pdf.DirectoryComparison) {
+	counts := make(map[string]int)
+	for _, file := range result.Files {
+		counts[file.Status]++
+		rate := "-"
+		if file.Comparison != nil {
+			rate = fmt.Sprintf("%d%%", int(math.Round(file.Comparison.Summary.MatchRate*100)))
+		}
+		fmt.Fprintf(stdout, "%s\t%s\t%s\n", file.Status, rate, file.Path)
+		if file.Error != "" {
+			fmt.Fprintf(stdout, "\t\t%s\n", file.Error)
+		}
+	}
+	fmt.Fprintf(stdout, "%d files: %d equal, %d changed, %d added, %d removed, %d failed\n", len(result.Files),
+		counts[pdf.FileEqual], counts[pdf.FileChanged], counts[pdf.FileAdded], counts[pdf.FileRemoved], counts[pdf.FileFailed])
+}
+
+func reportPath(dir string, file string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(file, filepath.Ext(file))+".html"))
+	return p, os.MkdirAll(filepath.Dir(p), 0o755)
+}
+
+// runBatch compares the files of two directories and exits
+func runBatch(leftDir string, rightDir string, glob string, options *pdf.CompareOptions, a *actions, threshold float64, quiet bool) {
+	result, err := pdf.CompareDirectories(context.Background(), leftDir, rightDir, glob, options)
+	if err != nil {
+		fatal("error:", err)
+	}
+
+	equal := true
+	failed := false
+	for _, file := range result.Files {
+		switch {
+		case file.Status == pdf.FileFailed:
+			failed = true
+		case file.Comparison == nil:
+			equal = false
+		case threshold >= 0:
+			equal = equal && file.Comparison.Summary.MatchRate >= threshold
+		default:
+			equal = equal && file.Status == pdf.FileEqual
+		}
+	}
+
+	switch a.output {
+	case "json":
+		if err := printJSON(result); err != nil {
+			fatal(err)
+		}
+	case "text":
+		printFiles(result)
+	}
+	for _, file := range result.Files {
+		if file.Comparison == nil {
+			continue
+		}
+		if a.output == "text" && (a.verbose || a.diff || a.pages || a.explain) {
+			fmt.Fprintf(stdout, "\n# %s\n", file.Path)
+		}
+		htmlPath := ""
+		if a.html != "" {
+			htmlPath, err = reportPath(a.html, file.Path)
+			if err != nil {
+				fatal(err)
+			}
+		}
+		a.run(file.Comparison, htmlPath)
+	}
+
+	if failed {
+		if !quiet {
+			fatal("error: some files failed to compare")
+		}
+		cleanup()
+		os.Exit(exitError)
+	}
+	exit(equal)
+}
@@ -0,0 +1,310 @@
+// Package cli implements the commands of pdftool, the single binaries like
+// pdfdiff run one of them.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"github.com/aelbrecht/pdfdump/internal/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Exit statuses of the commands
+const (
+	exitEqual     = 0
+	exitDifferent = 1
+	exitError     = 2
+)
+
+// The streams the commands read from and write to
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+var cleanups []func()
+
+func cleanup() {
+	for _, fn := range cleanups {
+		fn()
+	}
+	cleanups = nil
+}
+
+func fatal(v ...interface{}) {
+	log.Println(v...)
+	cleanup()
+	os.Exit(exitError)
+}
+
+func exit(equal bool) {
+	cleanup()
+	if equal {
+		os.Exit(exitEqual)
+	}
+	os.Exit(exitDifferent)
+}
+
+// globals are the flags shared by every command
+type globals struct {
+	rules   string
+	profile string
+	format  string
+	color   string
+}
+
+var global = globals{format: "text", color: "auto"}
+
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.rules, "rules", g.rules, "JSON or YAML file (.yaml, .yml) of keys, paths, types and patterns to ignore or mask and of tolerances")
+	fs.StringVar(&g.profile, "profile", g.profile, "profile of the rules file to apply on top of its other rules")
+	fs.StringVar(&g.format, "format", g.format, "output format, the formats a command supports are listed in its help")
+	fs.StringVar(&g.color, "color", g.color, "color the output: auto, always or never, auto colors terminals unless NO_COLOR is set")
+}
+
+func (g *globals) loadRules() *pdf.Rules {
+	if g.rules == "" {
+		if g.profile != "" {
+			fatal("error: a profile requires a rules file")
+		}
+		return nil
+	}
+	rules, err := pdf.LoadRules(g.rules, g.profile)
+	if err != nil {
+		fatal("error:", err)
+	}
+	return rules
+}
+
+func (g *globals) checkFormat(name string) {
+	formats := findCommand(name).formats
+	for _, f := range formats {
+		if g.format == f {
+			return
+		}
+	}
+	fatal(fmt.Sprintf("error: unknown format %s, expected %s", g.format, strings.Join(formats, ", ")))
+}
+
+func (g *globals) useColor() bool {
+	switch g.color {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" || stdout != os.Stdout {
+			return false
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+	fatal("error: unknown color mode", g.color)
+	return false
+}
+
+// ANSI colors of the diff output
+const (
+	colorGreen   = "92"
+	colorRed     = "91"
+	colorDefault = "39"
+)
+
+var colored = false
+
+func ansi(code string) string {
+	if !colored {
+		return ""
+	}
+	return "\033[" + code + "m"
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	// the first format is the default
+	formats []string
+	run     func(name string, args []string)
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"dump", "[FILE]", "print the objects of a document", []string{"text", "dot"}, runDump},
+		{"expand", "FILE...", "print the objects of documents as parsed, or write them to text files next to the documents", []string{"text"}, runExpand},
+		{"text", "[FILE]", "print the text of every page", []string{"text", "json"}, runText},
+		{"diff", "LEFT RIGHT", "compare the structure or text of two documents or directories of documents", []string{"text", "unified", "json"}, runDiff},
+		{"explore", "FILE", "explore a document interactively, starting at the trailer", []string{"text"}, runExplore},
+		{"serve", "[FILE]", "browse a document in a web browser", []string{"text"}, runServe},
+	}
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func program(name string) string {
+	base := filepath.Base(os.Args[0])
+	if base == "pdftool" {
+		return "pdftool " + name
+	}
+	return base
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	c := findCommand(name)
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		_, _ = fmt.Fprintf(out, "usage: %s [flags] %s\n\n%s\n\nflags:\n", program(name), c.args, c.summary)
+		fs.PrintDefaults()
+		_, _ = fmt.Fprintf(out, "\nformats: %s\n", strings.Join(c.formats, ", "))
+	}
+	global.register(fs)
+	return fs
+}
+
+func usage(out io.Writer) {
+	_, _ = fmt.Fprint(out, "usage: pdftool [global flags] COMMAND [flags] [arguments]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(out, "  %-8s %s\n", name, findCommand(name).summary)
+	}
+	_, _ = fmt.Fprint(out, "  help     print the help of a command\n\nglobal flags:\n")
+	fs := flag.NewFlagSet("pdftool", flag.ContinueOnError)
+	fs.SetOutput(out)
+	global.register(fs)
+	fs.PrintDefaults()
+	_, _ = fmt.Fprint(out, "\nA FILE of - reads the document from stdin, output goes to stdout.\n")
+}
+
+// Run runs a single command with its arguments.
+func Run(name string, args []string) {
+	if c := findCommand(name); c != nil {
+		c.run(name, args)
+		return
+	}
+	log.Println("error: unknown command", name)
+	usage(os.Stderr)
+	os.Exit(exitError)
+}
+
+// Main runs pdftool with the global flags, a command and its arguments.
+func Main(args []string) {
+	fs := flag.NewFlagSet("pdftool", flag.ExitOnError)
+	fs.Usage = func() { usage(fs.Output()) }
+	global.register(fs)
+	_ = fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		usage(os.Stderr)
+		os.Exit(exitError)
+	}
+	name := fs.Arg(0)
+	if name == "help" {
+		if fs.NArg() > 1 {
+			Run(fs.Arg(1), []string{"-h"})
+			return
+		}
+		usage(stdout)
+		return
+	}
+	Run(name, fs.Args()[1:])
+}
+
+func readInput(filePath string) []byte {
+	var data []byte
+	var err error
+	if filePath == "" || filePath == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		fatal("error:", err)
+	}
+	return data
+}
+
+func stdinFile() string {
+	f, err := os.CreateTemp("", "pdftool-*.pdf")
+	if err != nil {
+		fatal("error:", err)
+	}
+	cleanups = append(cleanups, func() { _ = os.Remove(f.Name()) })
+	_, err = io.Copy(f, stdin)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fatal("error:", err)
+	}
+	return f.Name()
+}
+
+func parseInput(filePath string) *pdf.PDF {
+	doc, err := parse(readInput(filePath))
+	if err != nil {
+		fatal("error:", err)
+	}
+	return doc
+}
+
+func parse(data []byte) (*pdf.PDF, error) {
+	parser, err := newParser(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := parser.Parse(); err != nil {
+		return nil, err
+	}
+	return parser.PDF(), nil
+}
+
+func newParser(data []byte) (*pdf.Parser, error) {
+	scanner, err := token.NewScanner(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return pdf.NewParser(scanner), nil
+}
+
+func inputArg(fs *flag.FlagSet) string {
+	if fs.NArg() > 1 {
+		fatal("error: expected one input file")
+	}
+	return fs.Arg(0)
+}
+
+func applyRules(doc *pdf.PDF) {
+	if rules := global.loadRules(); rules != nil {
+		if err := rules.Apply(doc); err != nil {
+			fatal("error:", err)
+		}
+	}
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runMain(t *testing.T, input []byte, args ...string) string {
+	t.Helper()
+	out := bytes.Buffer{}
+	stdin, stdout, global = bytes.NewReader(input), &out, globals{format: "text", color: "auto"}
+	t.Cleanup(func() {
+		stdin, stdout, global = os.Stdin, os.Stdout, globals{format: "text", color: "auto"}
+	})
+	Main(args)
+	return out.String()
+}
+
+func TestGlobalFlags(t *testing.T) {
+	doc := pdfgen.TextDocument([]string{"Hello world"}, []string{"Second page"}).Bytes()
+
+	before := runMain(t, doc, "-format", "json", "text", "-page", "2", "-")
+	after := runMain(t, doc, "text", "-page", "2", "-format", "json")
+	if before != after || !strings.Contains(before, `"text": "Second page"`) || strings.Contains(before, "Hello") {
+		t.Errorf("expected the JSON text of the second page, got\n%s\nand\n%s", before, after)
+	}
+	if out := runMain(t, doc, "dump", "-format", "dot"); !strings.HasPrefix(out, "digraph pdf {") {
+		t.Errorf("expected a graph, got\n%s", out)
+	}
+}
+
+func TestTextDiffWithoutColors(t *testing.T) {
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.pdf")
+	rightPath := filepath.Join(dir, "right.pdf")
+	if err := os.WriteFile(leftPath, pdfgen.TextDocument([]string{"Hello world"}).Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rightPath, pdfgen.TextDocument([]string{"Hello brave new world"}).Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+	result, err := pdf.CompareText(leftPath, rightPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printTextDiff(result, false)
+	if !strings.Contains(out.String(), "Hello {+brave new +}world") || strings.Contains(out.String(), "\033") {
+		t.Errorf("expected marked changes without escape sequences, got %q", out.String())
+	}
+}
+
+func TestGitHeader(t *testing.T) {
+	for _, c := range []struct {
+		format   string
+		quiet    bool
+		left     string
+		done     bool
+		expected string
+	}{
+		{"text", false, "old.pdf", false, "# doc.pdf\n"},
+		{"unified", false, os.DevNull, true, "# doc.pdf\nadded\n"},
+		{"json", false, "old.pdf", false, ""},
+		{"json", false, os.DevNull, true, "{\n  \"path\": \"doc.pdf\",\n  \"status\": \"added\"\n}\n"},
+		{"text", true, os.DevNull, true, ""},
+	} {
+		out := bytes.Buffer{}
+		stdout, global.format = &out, c.format
+		done := gitHeader("doc.pdf", c.left, "new.pdf", c.quiet)
+		stdout, global.format = os.Stdout, "text"
+		if done != c.done || out.String() != c.expected {
+			t.Errorf("%s quiet %v: expected %v %q, got %v %q", c.format, c.quiet, c.done, c.expected, done, out.String())
+		}
+	}
+}
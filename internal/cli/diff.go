@@ -0,0 +1,447 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+func writeDiffToDisk(diff *pdf.Comparison) error {
+	f1, err := createOutputFile(diff.LeftPath)
+	if err != nil {
+		return err
+	}
+	_, _ = f1.WriteString(diff.LeftOutput)
+	_ = f1.Close()
+
+	f2, err := createOutputFile(diff.RightPath)
+	if err != nil {
+		return err
+	}
+	_, _ = f2.WriteString(diff.RightOutput)
+	_ = f2.Close()
+
+	return nil
+}
+
+func createOutputFile(filePath string) (*os.File, error) {
+	dirName, fileName := path.Split(filePath)
+	fileName = strings.TrimSuffix(fileName, path.Ext(fileName))
+	return os.Create(path.Join(dirName, fileName+".txt"))
+}
+
+func printDivider(n int) {
+	fmt.Fprint(stdout, ansi(colorDefault))
+	for j := 0; j < n; j++ {
+		fmt.Fprint(stdout, "-")
+	}
+	fmt.Fprintln(stdout)
+}
+
+func printDiff(result *pdf.Comparison, printAll bool, context int) {
+	difference := result.String()
+	if printAll {
+		fmt.Fprintln(stdout, difference)
+		return
+	}
+
+	lines := strings.Split(difference, "\n")
+	maxLineLength := 0
+	for _, line := range lines {
+		tabs := strings.Count(line, "\t")
+		length := len(line) - tabs + 8*tabs
+		if length > maxLineLength && length < 300 {
+			maxLineLength = length
+		}
+	}
+
+	showDivider := false
+	printDivider(maxLineLength)
+	for i := 0; i < len(lines); i++ {
+		if len(lines[i]) == 0 {
+			continue
+		}
+		if lines[i][0] == '+' {
+			fmt.Fprintf(stdout, "%s%s\n", ansi(colorGreen), lines[i])
+			showDivider = true
+			continue
+		} else if lines[i][0] == '-' {
+			fmt.Fprintf(stdout, "%s%s\n", ansi(colorRed), lines[i])
+			showDivider = true
+			continue
+		}
+
+		isVisible := false
+		for j := -context; j <= context; j++ {
+			index := i + j
+			if index < 0 || index >= len(lines) || len(lines[index]) == 0 {
+				continue
+			}
+			c := lines[index][0]
+			if c == '+' || c == '-' {
+				isVisible = true
+				break
+			}
+		}
+		if isVisible {
+			fmt.Fprintf(stdout, "%s%s\n", ansi(colorDefault), lines[i])
+		} else if showDivider {
+			showDivider = false
+			printDivider(maxLineLength)
+		}
+	}
+	printDivider(maxLineLength)
+}
+
+func printTextDiff(result *pdf.TextComparison, printAll bool) {
+	for _, page := range result.Pages {
+		if !printAll && page.Equal() {
+			continue
+		}
+		if page.LeftPage == 0 {
+			fmt.Fprintf(stdout, "%s# Page %d (added)\n", ansi(colorDefault), page.RightPage)
+		} else if page.RightPage == 0 {
+			fmt.Fprintf(stdout, "%s# Page %d (removed)\n", ansi(colorDefault), page.LeftPage)
+		} else if page.LeftPage == page.RightPage {
+			fmt.Fprintf(stdout, "%s# Page %d\n", ansi(colorDefault), page.LeftPage)
+		} else {
+			fmt.Fprintf(stdout, "%s# Page %d -> %d\n", ansi(colorDefault), page.LeftPage, page.RightPage)
+		}
+		for _, d := range page.Diffs {
+			switch {
+			case d.Type == diffmatchpatch.DiffInsert && colored:
+				fmt.Fprintf(stdout, "%s%s", ansi(colorGreen), d.Text)
+			case d.Type == diffmatchpatch.DiffDelete && colored:
+				fmt.Fprintf(stdout, "%s%s", ansi(colorRed), d.Text)
+			case d.Type == diffmatchpatch.DiffInsert:
+				// Without colors changes are marked like wdiff does
+				fmt.Fprintf(stdout, "{+%s+}", d.Text)
+			case d.Type == diffmatchpatch.DiffDelete:
+				fmt.Fprintf(stdout, "[-%s-]", d.Text)
+			default:
+				fmt.Fprintf(stdout, "%s%s", ansi(colorDefault), d.Text)
+			}
+		}
+		fmt.Fprint(stdout, ansi(colorDefault))
+	}
+}
+
+func printSummary(summary *pdf.Summary) {
+	fmt.Fprintf(stdout, "comparing %d with %d objects\n", summary.LeftObjects, summary.RightObjects)
+	if summary.ExactMatches > 0 {
+		fmt.Fprintf(stdout, "exact matches:\t%d\n", summary.ExactMatches)
+	}
+	if summary.CloseMatches > 0 {
+		fmt.Fprintf(stdout, "close matches:\t%d\n", summary.CloseMatches)
+	}
+	if summary.DistantMatches > 0 {
+		fmt.Fprintf(stdout, "distant matches:\t%d\n", summary.DistantMatches)
+	}
+	fmt.Fprintf(stdout, "match rate:\t%d%%\n", int(math.Round(summary.MatchRate*100)))
+}
+
+func printPages(pages []*pdf.PageComparison) {
+	number := func(n int) string {
+		if n == 0 {
+			return "-"
+		}
+		return fmt.Sprint(n)
+	}
+	for _, page := range pages {
+		fmt.Fprintf(stdout, "%s\t%s\t%s\n", number(page.LeftPage), number(page.RightPage), page.Status)
+	}
+}
+
+func printExplanations(result *pdf.Comparison, printAll bool) {
+	for _, match := range result.Matches {
+		if !printAll && match.Score >= 1 {
+			continue
+		}
+		fmt.Fprintf(stdout, "# Object %d %d -> %d %d (%d%%)\n", match.Left.ObjectNumber, match.Left.ObjectGeneration,
+			match.Right.ObjectNumber, match.Right.ObjectGeneration, int(math.Round(match.Score*100)))
+		fmt.Fprint(stdout, result.ExplainMatch(match))
+	}
+}
+
+type keyTolerances map[string]float64
+
+func (k keyTolerances) String() string {
+	xs := make([]string, 0)
+	for key, tolerance := range k {
+		xs = append(xs, fmt.Sprintf("%s=%g", key, tolerance))
+	}
+	return strings.Join(xs, ",")
+}
+
+func (k keyTolerances) Set(value string) error {
+	key, tolerance, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected KEY=TOLERANCE, got %q", value)
+	}
+	v, err := strconv.ParseFloat(tolerance, 64)
+	if err != nil {
+		return err
+	}
+	k[strings.TrimPrefix(key, "/")] = v
+	return nil
+}
+
+// gitHeader writes the file git compares, added and removed files are done
+func gitHeader(gitPath string, leftPath string, rightPath string, quiet bool) bool {
+	text := !quiet && (global.format == "text" || global.format == "unified")
+	if text {
+		fmt.Fprintf(stdout, "# %s\n", gitPath)
+	}
+	if leftPath != os.DevNull && rightPath != os.DevNull {
+		return false
+	}
+	status := pdf.FileAdded
+	if rightPath == os.DevNull {
+		status = pdf.FileRemoved
+	}
+	if text {
+		fmt.Fprintln(stdout, status)
+	} else if !quiet && global.format == "json" {
+		if err := printJSON(&pdf.FileComparison{Path: gitPath, Status: status}); err != nil {
+			fatal(err)
+		}
+	}
+	return true
+}
+
+func runDiff(name string, args []string) {
+	fs := newFlagSet(name)
+	shouldDump := fs.Bool("dump", false, "write the comparable text files next to the inputs")
+	shouldDiff := fs.Bool("diff", false, "print the difference, the default when no other output is requested")
+	isVerbose := fs.Bool("verbose", false, "output stats")
+	printAll := fs.Bool("full", false, "print full difference")
+	explain := fs.Bool("explain", false, "output how the score of every inexact match is made up")
+	showPages := fs.Bool("pages", false, "output the status of every page")
+	htmlPath := fs.String("html", "", "write a side by side HTML report to this file, or a report per file into this directory when comparing directories")
+	mode := fs.String("mode", "struct", "compare the object structure (struct) or the text of each page (text)")
+	context := fs.Int("context", -1, "lines of context around changes, defaults to 3 for unified and 5 for text output")
+	strategy := fs.String("strategy", pdf.StrategyGreedy, "pair objects greedily (greedy), maximize the total score (global) or also score the linked objects (graph)")
+	matchThreshold := fs.Float64("match-threshold", pdf.DefaultCompareOptions().Threshold, "lowest score at which the global strategies pair two objects")
+	workers := fs.Int("workers", 0, "number of goroutines scoring objects, 0 uses one per CPU")
+	tolerance := fs.Float64("tolerance", 0, "absolute difference below which numbers are equal")
+	relativeTolerance := fs.Float64("relative-tolerance", 0, "difference relative to the larger number below which numbers are equal")
+	decimals := fs.Int("decimals", -1, "round numbers to this many decimals before comparing them, negative disables rounding")
+	perKey := keyTolerances{}
+	fs.Var(perKey, "key-tolerance", "absolute tolerance of the numbers of a dictionary key, e.g. MediaBox=0.01, can be repeated")
+	threshold := fs.Float64("threshold", -1, "lowest match rate between 0 and 1 at which the documents count as equivalent in struct mode, negative requires equal documents")
+	quiet := fs.Bool("quiet", false, "suppress output, only the exit status tells whether the documents are equivalent")
+	glob := fs.String("glob", "*.pdf", "pattern of the files compared when both inputs are directories, matched against the file name or, with a slash, the relative path")
+	leftPath := fs.String("left", "", "left input file or directory, instead of the first argument")
+	rightPath := fs.String("right", "", "right input file or directory, instead of the second argument")
+	_ = fs.Parse(args)
+
+	// GIT_EXTERNAL_DIFF: path old-file old-hex old-mode new-file new-hex new-mode
+	gitPath := ""
+	if fs.NArg() == 7 && *leftPath == "" && *rightPath == "" {
+		gitPath = fs.Arg(0)
+		*leftPath = fs.Arg(1)
+		*rightPath = fs.Arg(4)
+		if gitHeader(gitPath, *leftPath, *rightPath, *quiet) {
+			os.Exit(exitEqual)
+		}
+	}
+
+	if fs.NArg() == 2 && *leftPath == "" && *rightPath == "" {
+		*leftPath = fs.Arg(0)
+		*rightPath = fs.Arg(1)
+	} else if fs.NArg() != 0 && gitPath == "" {
+		fatal("error: expected two input files")
+	}
+	if *leftPath == "" || *rightPath == "" {
+		fatal("error: no input files specified")
+	}
+	if *leftPath == "-" && *rightPath == "-" {
+		fatal("error: only one input can be read from stdin")
+	}
+	leftName, rightName := *leftPath, *rightPath
+	for _, p := range []*string{leftPath, rightPath} {
+		if *p == "-" {
+			*p = stdinFile()
+		}
+		if _, err := os.Stat(*p); err != nil {
+			fatal("error:", err)
+		}
+	}
+	if !*shouldDiff && !*showPages && !*explain && *htmlPath == "" && !*shouldDump {
+		*shouldDiff = true
+	}
+	colored = global.useColor()
+
+	switch *mode {
+	case "struct":
+	case "text":
+		if isDir(*leftPath) || isDir(*rightPath) {
+			fatal("error: text mode does not support directories")
+		}
+		if !*shouldDiff && !*quiet {
+			fatal("error: text mode only supports the diff action")
+		}
+		if *threshold >= 0 {
+			fatal("error: text mode does not support -threshold")
+		}
+		global.checkFormat(name)
+		result, err := pdf.CompareText(*leftPath, *rightPath)
+		if err != nil {
+			fatal("error:", err)
+		}
+		result.LeftPath, result.RightPath = leftName, rightName
+		if *quiet {
+			exit(result.Equal())
+		}
+		if global.format == "json" {
+			if err := printJSON(result); err != nil {
+				fatal(err)
+			}
+			exit(result.Equal())
+		}
+		printTextDiff(result, *printAll)
+		exit(result.Equal())
+	default:
+		fatal("error: unknown mode", *mode)
+	}
+
+	global.checkFormat(name)
+	if *context < 0 {
+		*context = 5
+		if global.format == "unified" {
+			*context = 3
+		}
+	}
+
+	switch *strategy {
+	case pdf.StrategyGreedy, pdf.StrategyGlobal, pdf.StrategyGraph:
+	default:
+		fatal("error: unknown strategy", *strategy)
+	}
+
+	rules := global.loadRules()
+	options := &pdf.CompareOptions{
+		Strategy:  *strategy,
+		Threshold: *matchThreshold,
+		Workers:   *workers,
+		Tolerance: pdf.Tolerance{
+			Absolute: *tolerance,
+			Relative: *relativeTolerance,
+			Round:    *decimals >= 0,
+			Decimals: *decimals,
+		},
+		Tolerances: make(map[string]pdf.Tolerance, len(perKey)),
+		Rules:      rules,
+	}
+	for key, absolute := range perKey {
+		t := options.Tolerance
+		t.Absolute = absolute
+		options.Tolerances[key] = t
+	}
+	// Quiet runs still write the files they are asked for
+	output := global.format
+	if *quiet {
+		output = ""
+	}
+	a := &actions{
+		output:  output,
+		verbose: *isVerbose,
+		diff:    *shouldDiff,
+		full:    *printAll,
+		pages:   *showPages,
+		explain: *explain,
+		context: *context,
+		html:    *htmlPath,
+		dump:    *shouldDump,
+	}
+
+	if isDir(*leftPath) || isDir(*rightPath) {
+		if !isDir(*leftPath) || !isDir(*rightPath) {
+			fatal("error: either both or none of the inputs must be directories")
+		}
+		runBatch(*leftPath, *rightPath, *glob, options, a, *threshold, *quiet)
+		return
+	}
+
+	result, err := pdf.CompareWithOptions(*leftPath, *rightPath, options)
+	if err != nil {
+		fatal("error:", err)
+	}
+	result.LeftPath, result.RightPath = leftName, rightName
+	equal := result.Equal()
+	if *threshold >= 0 {
+		equal = result.Summary.MatchRate >= *threshold
+	}
+
+	hasAction := a.run(result, *htmlPath) || *quiet
+	if output == "json" {
+		if err := printJSON(result); err != nil {
+			fatal(err)
+		}
+		hasAction = true
+	}
+	if !hasAction {
+		fatal("error: no action specified")
+	}
+	// Git stops at the first external diff exiting with another status
+	if gitPath != "" {
+		exit(true)
+	}
+	exit(equal)
+}
+
+// actions are the outputs requested for a comparison
+type actions struct {
+	output  string
+	verbose bool
+	diff    bool
+	full    bool
+	pages   bool
+	explain bool
+	context int
+	html    string
+	dump    bool
+}
+
+func (a *actions) run(result *pdf.Comparison, htmlPath string) bool {
+	if a.verbose && a.output == "text" {
+		printSummary(&result.Summary)
+	}
+
+	hasAction := false
+	if a.diff && a.output == "text" {
+		printDiff(result, a.full, a.context)
+		hasAction = true
+	}
+	if a.diff && a.output == "unified" {
+		fmt.Fprint(stdout, result.Unified(a.context))
+		hasAction = true
+	}
+	if a.pages && a.output == "text" {
+		printPages(result.Pages)
+		hasAction = true
+	}
+	if a.explain && a.output == "text" {
+		printExplanations(result, a.full)
+		hasAction = true
+	}
+	if htmlPath != "" {
+		if err := writeReport(result, htmlPath); err != nil {
+			fatal(err)
+		}
+		hasAction = true
+	}
+	if a.dump {
+		err := writeDiffToDisk(result)
+		if err != nil {
+			fatal(err)
+		}
+		hasAction = true
+	}
+	return hasAction
+}
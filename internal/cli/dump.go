@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"strings"
+)
+
+func normalizeParsing() {
+	pdf.HideRandomKeys = true
+	pdf.HideVariableData = true
+	pdf.HideIdentifiers = true
+	pdf.HideStreamLength = true
+	pdf.TrimFontPrefix = true
+}
+
+func runDump(name string, args []string) {
+	fs := newFlagSet(name)
+	normalize := fs.Bool("normalize", false, "print a stable rendering without object numbers and variable data, e.g. as git textconv")
+	root := fs.String("root", "", "dot format: only include the objects reachable from this object number, e.g. 12 or 12,0")
+	depth := fs.Int("depth", 0, "dot format: number of references followed from the root, 0 follows all")
+	cluster := fs.Bool("cluster", false, "dot format: group the objects used by a single page with the page")
+	interactive := fs.Bool("i", false, "explore the document interactively, starting at the trailer")
+	addr := fs.String("serve", "", "browse the document in a web browser, served on this address, e.g. :8080")
+	_ = fs.Parse(args)
+
+	input := inputArg(fs)
+	global.checkFormat(name)
+	if *normalize {
+		normalizeParsing()
+	}
+	doc := parseInput(input)
+	applyRules(doc)
+
+	if *addr != "" {
+		if err := serve(*addr, input, doc); err != nil {
+			fatal("error:", err)
+		}
+		return
+	}
+	if *interactive {
+		exploreInput(input, doc)
+		return
+	}
+	if global.format == "dot" {
+		opts := &pdf.DotOptions{Depth: *depth, ClusterPages: *cluster}
+		if *root != "" {
+			key := *root
+			if !strings.Contains(key, ",") {
+				key += ",0"
+			}
+			o, ok := doc.Objects[key]
+			if !ok {
+				fatal("error: no object", *root)
+			}
+			opts.Root = o
+		}
+		_, _ = fmt.Fprint(stdout, doc.Dot(opts))
+		return
+	}
+
+	if *normalize {
+		_, _ = fmt.Fprint(stdout, doc.Normalized())
+		return
+	}
+	_, _ = fmt.Fprint(stdout, doc.String())
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+func expandPDF(filePath string, out io.Writer) {
+	parser, err := newParser(readInput(filePath))
+	if err == nil {
+		err = parser.Parse()
+	}
+	if err != nil {
+		fatal("error:", err)
+	}
+	parser.Dump(out)
+}
+
+func runExpand(name string, args []string) {
+	fs := newFlagSet(name)
+	write := fs.Bool("write", false, "write the objects of every document to a .txt file next to it instead of stdout")
+	_ = fs.Parse(args)
+	global.checkFormat(name)
+
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, filePath := range inputs {
+		if !*write || filePath == "-" {
+			expandPDF(filePath, stdout)
+			continue
+		}
+		dirName, fileName := path.Split(filePath)
+		fileName = strings.TrimSuffix(fileName, path.Ext(fileName))
+		o, err := os.Create(path.Join(dirName, fileName+".txt"))
+		if err != nil {
+			fatal("error:", err)
+		}
+		expandPDF(filePath, o)
+		if err := o.Close(); err != nil {
+			fatal("error:", err)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const exploreHelp = `commands:
+  ls                 list the entries of the current node
+  cd PATH            enter an entry, references are followed, e.g. cd Root/Pages/Kids/0
+  cd ..              go up one level, cd / returns to the trailer
+  back               return to the previous location
+  obj N [G]          jump to an object
+  show               print the current node
+  stream             print the decoded data of the current stream
+  find TEXT          list the objects whose rendering contains the text
+  pwd                print the path of the current node
+  help               print this help
+  quit               leave
+`
+
+// Streams larger than this are cut off when printed
+const maxStreamOutput = 64 * 1024
+
+type frame struct {
+	label string
+	value pdf.ObjectType
+}
+
+type explorer struct {
+	doc     *pdf.PDF
+	path    []frame
+	history [][]frame
+	out     io.Writer
+}
+
+func newExplorer(doc *pdf.PDF, out io.Writer) *explorer {
+	e := &explorer{doc: doc, out: out}
+	e.path = e.root()
+	return e
+}
+
+func (e *explorer) root() []frame {
+	var trailer pdf.ObjectType = pdf.NewDictionary(nil)
+	if e.doc.Trailer != nil {
+		trailer = e.doc.Trailer
+	}
+	return []frame{{"trailer", trailer}}
+}
+
+func (e *explorer) current() pdf.ObjectType {
+	return e.path[len(e.path)-1].value
+}
+
+func identifier(o *pdf.Object) string {
+	return fmt.Sprintf("%d %d", o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration)
+}
+
+func entries(o pdf.ObjectType) []frame {
+	output := make([]frame, 0)
+	switch v := o.(type) {
+	case *pdf.Object:
+		for i, child := range v.Children {
+			switch c := child.(type) {
+			case *pdf.Dictionary:
+				output = append(output, entries(c)...)
+			case *pdf.Stream:
+				output = append(output, frame{"stream", c})
+			default:
+				output = append(output, frame{strconv.Itoa(i), c})
+			}
+		}
+	case *pdf.Dictionary:
+		for _, pair := range v.Value {
+			output = append(output, frame{pair.K.String(), pair.V})
+		}
+	case *pdf.Array:
+		for i, child := range v.Value {
+			output = append(output, frame{strconv.Itoa(i), child})
+		}
+	}
+	return output
+}
+
+func summary(o pdf.ObjectType) string {
+	switch v := o.(type) {
+	case *pdf.Dictionary:
+		return fmt.Sprintf("Dict( size:%d )", len(v.Value))
+	case *pdf.Array:
+		return fmt.Sprintf("Array( size:%d )", len(v.Value))
+	case *pdf.Stream:
+		return fmt.Sprintf("Stream( size:%d )", len(v.Value))
+	case *pdf.ObjectReference:
+		if v.Value == nil {
+			return fmt.Sprintf("-> %d %d (missing)", v.Link.ObjectNumber, v.Link.ObjectGeneration)
+		}
+		text := "-> " + identifier(v.Value)
+		if d := v.Value.Dictionary(); d != nil {
+			if t, ok := d.Get("Type").(*pdf.Label); ok {
+				text += " /" + t.String()
+			}
+		}
+		return text
+	case *pdf.Object:
+		return "Object( " + identifier(v) + " )"
+	}
+	return strings.SplitN(o.String(), "\n", 2)[0]
+}
+
+func (e *explorer) pwd() string {
+	if len(e.path) == 1 {
+		return "/"
+	}
+	buffer := strings.Builder{}
+	for _, f := range e.path[1:] {
+		buffer.WriteString("/" + f.label)
+		if o, ok := f.value.(*pdf.Object); ok {
+			buffer.WriteString("(" + identifier(o) + ")")
+		}
+	}
+	return buffer.String()
+}
+
+func (e *explorer) jump(path []frame) {
+	e.history = append(e.history, e.path)
+	e.path = path
+}
+
+func enter(path []frame, name string) ([]frame, error) {
+	for _, entry := range entries(path[len(path)-1].value) {
+		if entry.label != name {
+			continue
+		}
+		if ref, ok := entry.value.(*pdf.ObjectReference); ok {
+			if ref.Value == nil {
+				return nil, fmt.Errorf("object %d %d is missing", ref.Link.ObjectNumber, ref.Link.ObjectGeneration)
+			}
+			entry.value = ref.Value
+		}
+		return append(append([]frame{}, path...), entry), nil
+	}
+	return nil, fmt.Errorf("no entry %s", name)
+}
+
+func (e *explorer) cd(target string) error {
+	path := e.path
+	if strings.HasPrefix(target, "/") {
+		path = e.root()
+	}
+	for _, name := range strings.Split(strings.Trim(target, "/"), "/") {
+		switch name {
+		case "", ".":
+		case "..":
+			if len(path) > 1 {
+				path = path[:len(path)-1]
+			}
+		default:
+			var err error
+			if path, err = enter(path, name); err != nil {
+				return err
+			}
+		}
+	}
+	e.jump(path)
+	return nil
+}
+
+func (e *explorer) obj(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("expected an object number and an optional generation")
+	}
+	key := args[0] + ",0"
+	if len(args) == 2 {
+		key = args[0] + "," + args[1]
+	}
+	o, ok := e.doc.Objects[key]
+	if !ok {
+		return fmt.Errorf("no object %s", strings.Join(args, " "))
+	}
+	e.jump(append(e.root(), frame{"obj", o}))
+	return nil
+}
+
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *explorer) stream() error {
+	var s *pdf.Stream
+	switch v := e.current().(type) {
+	case *pdf.Stream:
+		s = v
+	case *pdf.Object:
+		_, s = v.Stream()
+	}
+	if s == nil {
+		return fmt.Errorf("not a stream")
+	}
+	data := s.Data()
+	truncated := len(data) > maxStreamOutput
+	if truncated {
+		data = data[:maxStreamOutput]
+	}
+	if printable(data) {
+		_, _ = fmt.Fprintln(e.out, string(data))
+	} else {
+		_, _ = fmt.Fprint(e.out, hex.Dump(data))
+	}
+	if truncated {
+		_, _ = fmt.Fprintf(e.out, "... %d bytes in total\n", len(s.Data()))
+	}
+	return nil
+}
+
+func (e *explorer) find(text string) {
+	text = strings.ToLower(text)
+	for _, o := range e.doc.SortedObjects() {
+		for _, line := range strings.Split(o.String(), "\n") {
+			if strings.Contains(strings.ToLower(line), text) {
+				_, _ = fmt.Fprintf(e.out, "%s\t%s\n", identifier(o), strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+}
+
+func (e *explorer) run(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+	args := fields[1:]
+	var err error
+	switch fields[0] {
+	case "ls":
+		for _, entry := range entries(e.current()) {
+			_, _ = fmt.Fprintf(e.out, "%s\t%s\n", entry.label, summary(entry.value))
+		}
+	case "cd":
+		if len(args) == 0 {
+			args = []string{"/"}
+		}
+		err = e.cd(strings.Join(args, " "))
+	case "back":
+		if len(e.history) == 0 {
+			err = fmt.Errorf("no previous location")
+		} else {
+			e.path = e.history[len(e.history)-1]
+			e.history = e.history[:len(e.history)-1]
+		}
+	case "obj":
+		err = e.obj(args)
+	case "show":
+		_, _ = fmt.Fprintln(e.out, strings.TrimRight(e.current().String(), "\n"))
+	case "stream":
+		err = e.stream()
+	case "find":
+		if len(args) == 0 {
+			err = fmt.Errorf("expected the text to search")
+		} else {
+			e.find(strings.Join(args, " "))
+		}
+	case "pwd":
+		_, _ = fmt.Fprintln(e.out, e.pwd())
+	case "help":
+		_, _ = fmt.Fprint(e.out, exploreHelp)
+	case "quit", "exit":
+		return false
+	default:
+		err = fmt.Errorf("unknown command %s, try help", fields[0])
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(e.out, "error:", err)
+	}
+	return true
+}
+
+func explore(doc *pdf.PDF, in io.Reader, out io.Writer) {
+	e := newExplorer(doc, out)
+	scanner := bufio.NewScanner(in)
+	for {
+		_, _ = fmt.Fprintf(out, "%s> ", e.pwd())
+		if !scanner.Scan() || !e.run(scanner.Text()) {
+			_, _ = fmt.Fprintln(out)
+			return
+		}
+	}
+}
+
+func exploreInput(input string, doc *pdf.PDF) {
+	if input == "" || input == "-" {
+		fatal("error: cannot explore a document read from stdin")
+	}
+	explore(doc, stdin, stdout)
+}
+
+func runExplore(name string, args []string) {
+	fs := newFlagSet(name)
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fatal("error: expected one input file")
+	}
+	global.checkFormat(name)
+	doc := parseInput(fs.Arg(0))
+	applyRules(doc)
+	exploreInput(fs.Arg(0), doc)
+}
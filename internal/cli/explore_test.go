@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"strings"
+	"testing"
+)
+
+func TestExplore(t *testing.T) {
+	doc, err := parse(pdfgen.TextDocument([]string{"Hello world"}).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commands := "cd Root/Pages/Kids/0\npwd\ncd Contents\nstream\nback\npwd\nobj 3\npwd\nfind helvetica\ncd missing\n"
+	out := bytes.Buffer{}
+	explore(doc, strings.NewReader(commands), &out)
+	for _, expected := range []string{
+		"> /Root(1 0)/Pages(2 0)/Kids/0(5 0)\n",
+		"(Hello world) Tj\n",
+		"> /obj(3 0)\n",
+		"3 0\tBaseFont -> Helvetica,\n",
+		"error: no entry missing\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+)
+
+// Unchanged lines kept around a change
+const reportContext = 3
+
+type reportRow struct {
+	Left  string
+	Right string
+	Class string
+}
+
+type reportChunk struct {
+	Collapsed bool
+	Rows      []reportRow
+}
+
+type reportObject struct {
+	ID        string
+	Alias     string
+	Title     string
+	Score     int
+	Class     string
+	Changes   []pdf.Change
+	Chunks    []reportChunk
+	Collapsed bool
+}
+
+type reportLink struct {
+	Anchor string
+	Label  string
+}
+
+type reportPage struct {
+	ID        string
+	LeftPage  string
+	RightPage string
+	Status    string
+	Objects   []reportLink
+}
+
+type report struct {
+	LeftPath  string
+	RightPath string
+	Summary   pdf.Summary
+	MatchRate int
+	Pages     []reportPage
+	Objects   []reportObject
+}
+
+func objectAnchor(side string, id pdf.ObjectIdentifier) string {
+	return fmt.Sprintf("%s-%d-%d", side, id.ObjectNumber, id.ObjectGeneration)
+}
+
+func sideBySide(difference string) []reportRow {
+	rows := make([]reportRow, 0)
+	removed := make([]string, 0)
+	added := make([]string, 0)
+	flush := func() {
+		for i := 0; i < len(removed) || i < len(added); i++ {
+			row := reportRow{Class: "changed"}
+			if i < len(removed) {
+				row.Left = removed[i]
+			} else {
+				row.Class = "inserted"
+			}
+			if i < len(added) {
+				row.Right = added[i]
+			} else {
+				row.Class = "deleted"
+			}
+			rows = append(rows, row)
+		}
+		removed = removed[:0]
+		added = added[:0]
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(difference, "\n"), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		text := line[2:]
+		switch line[0] {
+		case '-':
+			if len(added) > 0 {
+				flush()
+			}
+			removed = append(removed, text)
+		case '+':
+			added = append(added, text)
+		default:
+			flush()
+			rows = append(rows, reportRow{Left: text, Right: text, Class: "equal"})
+		}
+	}
+	flush()
+	return rows
+}
+
+func collapse(rows []reportRow) []reportChunk {
+	visible := make([]bool, len(rows))
+	for i, row := range rows {
+		if row.Class == "equal" {
+			continue
+		}
+		for j := i - reportContext; j <= i+reportContext; j++ {
+			if j >= 0 && j < len(rows) {
+				visible[j] = true
+			}
+		}
+	}
+	chunks := make([]reportChunk, 0)
+	for i, row := range rows {
+		collapsed := !visible[i]
+		if len(chunks) == 0 || chunks[len(chunks)-1].Collapsed != collapsed {
+			chunks = append(chunks, reportChunk{Collapsed: collapsed})
+		}
+		chunks[len(chunks)-1].Rows = append(chunks[len(chunks)-1].Rows, row)
+	}
+	return chunks
+}
+
+func unmatchedObject(result *pdf.Comparison, side string, id pdf.ObjectIdentifier, doc *pdf.PDF) reportObject {
+	rows := make([]reportRow, 0)
+	text := strings.TrimSuffix(result.RenderObject(doc.Objects[id.Hash()]), "\n")
+	for _, line := range strings.Split(text, "\n") {
+		if side == "left" {
+			rows = append(rows, reportRow{Left: line, Class: "deleted"})
+		} else {
+			rows = append(rows, reportRow{Right: line, Class: "inserted"})
+		}
+	}
+	title := fmt.Sprintf("Object %d %d (removed)", id.ObjectNumber, id.ObjectGeneration)
+	class := "deleted"
+	if side == "right" {
+		title = fmt.Sprintf("Object %d %d (added)", id.ObjectNumber, id.ObjectGeneration)
+		class = "inserted"
+	}
+	return reportObject{
+		ID:     objectAnchor(side, id),
+		Title:  title,
+		Class:  class,
+		Chunks: []reportChunk{{Rows: rows}},
+	}
+}
+
+func pageObjects(side string, page *pdf.Page) []reportLink {
+	links := make([]reportLink, 0)
+	if page == nil {
+		return links
+	}
+	content, resources := page.Objects()
+	for _, o := range append([]*pdf.Object{page.Object}, append(content, resources...)...) {
+		links = append(links, reportLink{
+			Anchor: objectAnchor(side, o.Identifier),
+			Label:  fmt.Sprintf("%s %d %d", side, o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration),
+		})
+	}
+	return links
+}
+
+func buildReport(result *pdf.Comparison) *report {
+	r := &report{
+		LeftPath:  result.LeftPath,
+		RightPath: result.RightPath,
+		Summary:   result.Summary,
+		MatchRate: int(math.Round(result.Summary.MatchRate * 100)),
+	}
+
+	leftPages := result.LeftDocument.Pages()
+	rightPages := result.RightDocument.Pages()
+	number := func(n int) string {
+		if n == 0 {
+			return "-"
+		}
+		return fmt.Sprint(n)
+	}
+	for i, page := range result.Pages {
+		entry := reportPage{
+			ID:        fmt.Sprintf("page-%d", i+1),
+			LeftPage:  number(page.LeftPage),
+			RightPage: number(page.RightPage),
+			Status:    page.Status,
+		}
+		if page.LeftPage > 0 {
+			entry.Objects = append(entry.Objects, pageObjects("left", leftPages[page.LeftPage-1])...)
+		} else {
+			entry.Objects = append(entry.Objects, pageObjects("right", rightPages[page.RightPage-1])...)
+		}
+		r.Pages = append(r.Pages, entry)
+	}
+
+	for _, match := range result.Matches {
+		chunks := collapse(sideBySide(match.Diff.String()))
+		class := "equal"
+		if len(match.Changes) > 0 {
+			class = "changed"
+		}
+		r.Objects = append(r.Objects, reportObject{
+			ID:        objectAnchor("left", match.Left),
+			Alias:     objectAnchor("right", match.Right),
+			Title:     fmt.Sprintf("Object %d %d -> %d %d", match.Left.ObjectNumber, match.Left.ObjectGeneration, match.Right.ObjectNumber, match.Right.ObjectGeneration),
+			Score:     int(math.Round(match.Score * 100)),
+			Class:     class,
+			Changes:   match.Changes,
+			Chunks:    chunks,
+			Collapsed: len(match.Changes) == 0,
+		})
+	}
+	for _, id := range result.LeftUnmatched {
+		r.Objects = append(r.Objects, unmatchedObject(result, "left", id, result.LeftDocument))
+	}
+	for _, id := range result.RightUnmatched {
+		r.Objects = append(r.Objects, unmatchedObject(result, "right", id, result.RightDocument))
+	}
+	return r
+}
+
+func writeReport(result *pdf.Comparison, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	err = reportTemplate.Execute(f, buildReport(result))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>pdfdiff {{.LeftPath}} {{.RightPath}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; }
+.summary td, .pages td, .pages th { padding: 0.2em 1em 0.2em 0; text-align: left; }
+.object { margin: 1.5em 0; border: 1px solid #ccc; }
+.object > summary, .object > h3 { margin: 0; padding: 0.4em; background: #f4f4f4; cursor: pointer; font-size: 1em; }
+.object.changed > summary { background: #fff4d6; }
+.object.inserted > summary, .object.inserted > h3 { background: #e3f7e3; }
+.object.deleted > summary, .object.deleted > h3 { background: #fbe3e3; }
+.changes { margin: 0.4em; font-family: monospace; }
+.diff { width: 100%; table-layout: fixed; font-family: monospace; font-size: 0.9em; }
+.diff td { white-space: pre-wrap; tab-size: 4; vertical-align: top; padding: 0 0.4em; width: 50%; }
+.diff tr.changed td { background: #fff4d6; }
+.diff tr.inserted td:last-child { background: #e3f7e3; }
+.diff tr.deleted td:first-child { background: #fbe3e3; }
+.folded > summary { color: #666; font-style: italic; cursor: pointer; padding: 0 0.4em; }
+</style>
+</head>
+<body>
+<h1>pdfdiff report</h1>
+<table class="summary">
+<tr><td>left</td><td>{{.LeftPath}}</td><td>{{.Summary.LeftObjects}} objects</td></tr>
+<tr><td>right</td><td>{{.RightPath}}</td><td>{{.Summary.RightObjects}} objects</td></tr>
+<tr><td>exact matches</td><td>{{.Summary.ExactMatches}}</td></tr>
+<tr><td>close matches</td><td>{{.Summary.CloseMatches}}</td></tr>
+<tr><td>distant matches</td><td>{{.Summary.DistantMatches}}</td></tr>
+<tr><td>changed</td><td>{{.Summary.Changed}}</td></tr>
+<tr><td>unmatched</td><td>{{.Summary.LeftUnmatched}} left, {{.Summary.RightUnmatched}} right</td></tr>
+<tr><td>match rate</td><td>{{.MatchRate}}%</td></tr>
+</table>
+<h2>Pages</h2>
+<table class="pages">
+<tr><th>left</th><th>right</th><th>status</th><th>objects</th></tr>
+{{- range .Pages}}
+<tr id="{{.ID}}"><td><a href="#{{.ID}}">{{.LeftPage}}</a></td><td>{{.RightPage}}</td><td>{{.Status}}</td><td>
+{{- range $i, $link := .Objects}}{{if $i}}, {{end}}<a href="#{{$link.Anchor}}">{{$link.Label}}</a>{{end -}}
+</td></tr>
+{{- end}}
+</table>
+<h2>Objects</h2>
+{{- range .Objects}}
+{{- if .Alias}}
+<details class="object {{.Class}}" id="{{.ID}}"{{if not .Collapsed}} open{{end}}>
+<summary><a id="{{.Alias}}" href="#{{.ID}}">{{.Title}}</a> ({{.Score}}%)</summary>
+{{- if .Changes}}
+<div class="changes">
+{{- range .Changes}}
+<div>{{.String}}</div>
+{{- end}}
+</div>
+{{- end}}
+{{template "chunks" .Chunks}}
+</details>
+{{- else}}
+<div class="object {{.Class}}" id="{{.ID}}">
+<h3><a href="#{{.ID}}">{{.Title}}</a></h3>
+{{template "chunks" .Chunks}}
+</div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+{{define "chunks"}}
+{{- range .}}
+{{- if .Collapsed}}
+<details class="folded"><summary>{{len .Rows}} unchanged lines</summary>
+{{template "rows" .Rows}}
+</details>
+{{- else}}
+{{template "rows" .Rows}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{define "rows"}}<table class="diff">
+{{- range .}}
+<tr class="{{.Class}}"><td>{{.Left}}</td><td>{{.Right}}</td></tr>
+{{- end}}
+</table>{{end}}
+`))
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	document := func(title string) *pdf.PDF {
+		d := pdfgen.TextDocument([]string{"Hello world"})
+		d.SetInfo(d.Add("<< /Producer (pdfgen) /Creator (pdfgen) /Title (" + title + ") >>"))
+		doc, err := parse(d.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	injected := `<img src="https://example.com/x.png"><script>alert</script>`
+	result, err := pdf.CompareDocuments(document("Report"), document(injected), pdf.DefaultCompareOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportPath := filepath.Join(t.TempDir(), "report.html")
+	if err := writeReport(result, reportPath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(data)
+
+	if result.Summary.Changed != 1 {
+		t.Fatalf("expected the info dictionary to change, got %+v", result.Summary)
+	}
+	if strings.Contains(output, injected) || strings.Contains(output, "<script") || strings.Contains(output, "<img") {
+		t.Errorf("expected the changed title to be escaped, got\n%s", output)
+	}
+	if !strings.Contains(output, "&lt;script&gt;alert&lt;/script&gt;") {
+		t.Errorf("expected the escaped title in the report, got\n%s", output)
+	}
+	for _, attr := range regexp.MustCompile(`\s(src|href)="([^"]*)"`).FindAllStringSubmatch(output, -1) {
+		if attr[1] == "src" || !strings.HasPrefix(attr[2], "#") {
+			t.Errorf("expected only links within the report, got %s", attr[0])
+		}
+	}
+	if strings.Contains(output, "<link") {
+		t.Error("expected no external stylesheets")
+	}
+}
@@ -0,0 +1,424 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+	"html"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Binary streams previewed as hex are cut off after this many bytes
+const maxHexPreview = 1024
+
+type server struct {
+	name      string
+	doc       *pdf.PDF
+	referrers map[*pdf.Object][]*pdf.Object
+	mux       *http.ServeMux
+}
+
+type serverObject struct {
+	Link    string
+	Label   string
+	Type    string
+	Summary string
+}
+
+type serverPage struct {
+	Name     string
+	Title    string
+	Trailer  template.HTML
+	Pages    []serverObject
+	Objects  []serverObject
+	Query    string
+	Results  []serverObject
+	Object   template.HTML
+	Stream   template.HTML
+	Referrer []serverObject
+}
+
+func newServer(name string, doc *pdf.PDF) *server {
+	s := &server{
+		name:      name,
+		doc:       doc,
+		referrers: make(map[*pdf.Object][]*pdf.Object),
+		mux:       http.NewServeMux(),
+	}
+	for _, o := range doc.SortedObjects() {
+		for _, target := range references(o) {
+			s.referrers[target] = append(s.referrers[target], o)
+		}
+	}
+	s.mux.HandleFunc("/", s.index)
+	s.mux.HandleFunc("/object/", s.object)
+	s.mux.HandleFunc("/stream/", s.stream)
+	s.mux.HandleFunc("/search", s.search)
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func references(o pdf.ObjectType) []*pdf.Object {
+	output := make([]*pdf.Object, 0)
+	seen := make(map[*pdf.Object]bool)
+	var walk func(o pdf.ObjectType)
+	walk = func(o pdf.ObjectType) {
+		switch v := o.(type) {
+		case *pdf.Object:
+			for _, child := range v.Children {
+				walk(child)
+			}
+		case *pdf.Dictionary:
+			for _, pair := range v.Value {
+				walk(pair.V)
+			}
+		case *pdf.Array:
+			for _, child := range v.Value {
+				walk(child)
+			}
+		case *pdf.ObjectReference:
+			if v.Value != nil && !seen[v.Value] {
+				seen[v.Value] = true
+				output = append(output, v.Value)
+			}
+		}
+	}
+	walk(o)
+	return output
+}
+
+func objectLink(o *pdf.Object) string {
+	return fmt.Sprintf("/object/%d/%d", o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration)
+}
+
+func typeOf(o *pdf.Object) string {
+	d := o.Dictionary()
+	if d == nil {
+		return ""
+	}
+	names := make([]string, 0)
+	for _, key := range []string{"Type", "Subtype"} {
+		if l, ok := d.Get(key).(*pdf.Label); ok {
+			names = append(names, "/"+l.String())
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func listObject(o *pdf.Object) serverObject {
+	item := serverObject{Link: objectLink(o), Label: identifier(o), Type: typeOf(o)}
+	if d, st := o.Stream(); st != nil {
+		item.Summary = fmt.Sprintf("stream, %d bytes", len(st.Value))
+	} else if d != nil {
+		item.Summary = fmt.Sprintf("%d entries", len(d.Value))
+	}
+	return item
+}
+
+func renderValue(o pdf.ObjectType) string {
+	switch v := o.(type) {
+	case *pdf.Object:
+		items := make([]string, 0)
+		for _, child := range v.Children {
+			items = append(items, "<li>"+renderValue(child)+"</li>")
+		}
+		return "<ul class=\"tree\">" + strings.Join(items, "") + "</ul>"
+	case *pdf.Dictionary:
+		items := make([]string, 0)
+		for _, pair := range v.Value {
+			items = append(items, fmt.Sprintf("<li><span class=\"key\">/%s</span> %s</li>",
+				html.EscapeString(pair.K.String()), renderValue(pair.V)))
+		}
+		return fmt.Sprintf("<details open><summary>Dict( size:%d )</summary><ul class=\"tree\">%s</ul></details>",
+			len(v.Value), strings.Join(items, ""))
+	case *pdf.Array:
+		items := make([]string, 0)
+		for i, child := range v.Value {
+			items = append(items, fmt.Sprintf("<li><span class=\"key\">[%d]</span> %s</li>", i, renderValue(child)))
+		}
+		return fmt.Sprintf("<details open><summary>Array( size:%d )</summary><ul class=\"tree\">%s</ul></details>",
+			len(v.Value), strings.Join(items, ""))
+	case *pdf.ObjectReference:
+		if v.Value == nil {
+			return html.EscapeString(fmt.Sprintf("%d %d R (missing)", v.Link.ObjectNumber, v.Link.ObjectGeneration))
+		}
+		return fmt.Sprintf("<a href=\"%s\">%s R</a> <span class=\"type\">%s</span>",
+			objectLink(v.Value), identifier(v.Value), html.EscapeString(typeOf(v.Value)))
+	case *pdf.Stream:
+		return fmt.Sprintf("stream, %d bytes encoded, %d bytes decoded", len(v.Value), len(v.Data()))
+	}
+	return html.EscapeString(o.String())
+}
+
+func streamType(dict *pdf.Dictionary, data []byte) string {
+	filter := ""
+	switch f := dict.Get("Filter").(type) {
+	case *pdf.Label:
+		filter = f.String()
+	case *pdf.Array:
+		if len(f.Value) > 0 {
+			filter = f.Value[len(f.Value)-1].String()
+		}
+	}
+	switch filter {
+	case "DCTDecode":
+		return "image/jpeg"
+	case "JPXDecode":
+		return "image/jp2"
+	}
+	if l, ok := dict.Get("Subtype").(*pdf.Label); ok && l.String() == "Image" {
+		if _, err := rawImage(dict, data); err == nil {
+			return "image/png"
+		}
+	}
+	if printable(data) {
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+func rawImage(dict *pdf.Dictionary, data []byte) (image.Image, error) {
+	width, ok1 := dict.Get("Width").(*pdf.Number)
+	height, ok2 := dict.Get("Height").(*pdf.Number)
+	bits, ok3 := dict.Get("BitsPerComponent").(*pdf.Number)
+	space, ok4 := dict.Get("ColorSpace").(*pdf.Label)
+	if !ok1 || !ok2 || !ok3 || !ok4 || bits.Value != 8 {
+		return nil, fmt.Errorf("unsupported image")
+	}
+	components := 0
+	switch space.String() {
+	case "DeviceGray":
+		components = 1
+	case "DeviceRGB":
+		components = 3
+	default:
+		return nil, fmt.Errorf("unsupported color space %s", space.String())
+	}
+	w, h := int(width.Value), int(height.Value)
+	if w <= 0 || h <= 0 || len(data) < w*h*components {
+		return nil, fmt.Errorf("image data too short")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := (y*w + x) * components
+			if components == 1 {
+				img.Set(x, y, color.Gray{Y: data[i]})
+			} else {
+				img.Set(x, y, color.RGBA{R: data[i], G: data[i+1], B: data[i+2], A: 255})
+			}
+		}
+	}
+	return img, nil
+}
+
+func (s *server) lookup(r *http.Request, prefix string) *pdf.Object {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"), "/")
+	if len(parts) != 2 {
+		return nil
+	}
+	return s.doc.Objects[parts[0]+","+parts[1]]
+}
+
+func (s *server) render(w http.ResponseWriter, page *serverPage) {
+	page.Name = s.name
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := serverTemplate.Execute(w, page); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page := &serverPage{Title: "Document"}
+	if s.doc.Trailer != nil {
+		page.Trailer = template.HTML(renderValue(s.doc.Trailer))
+	}
+	for _, p := range s.doc.Pages() {
+		item := listObject(p.Object)
+		item.Type = fmt.Sprintf("Page %d", p.Number)
+		page.Pages = append(page.Pages, item)
+	}
+	for _, o := range s.doc.SortedObjects() {
+		page.Objects = append(page.Objects, listObject(o))
+	}
+	s.render(w, page)
+}
+
+func (s *server) object(w http.ResponseWriter, r *http.Request) {
+	o := s.lookup(r, "/object/")
+	if o == nil {
+		http.NotFound(w, r)
+		return
+	}
+	page := &serverPage{
+		Title:  "Object " + identifier(o) + " " + typeOf(o),
+		Object: template.HTML(renderValue(o)),
+	}
+	for _, referrer := range s.referrers[o] {
+		page.Referrer = append(page.Referrer, listObject(referrer))
+	}
+	if dict, st := o.Stream(); st != nil {
+		data := st.Data()
+		link := fmt.Sprintf("/stream/%d/%d", o.Identifier.ObjectNumber, o.Identifier.ObjectGeneration)
+		if dict == nil {
+			dict = pdf.NewDictionary(nil)
+		}
+		contentType := streamType(dict, data)
+		switch {
+		case strings.HasPrefix(contentType, "image/"):
+			page.Stream = template.HTML(fmt.Sprintf("<img src=\"%s\" alt=\"stream\">", link))
+		case strings.HasPrefix(contentType, "text/"):
+			preview := data
+			if len(preview) > maxStreamOutput {
+				preview = preview[:maxStreamOutput]
+			}
+			page.Stream = template.HTML("<pre>" + html.EscapeString(string(preview)) + "</pre>")
+		default:
+			preview := data
+			if len(preview) > maxHexPreview {
+				preview = preview[:maxHexPreview]
+			}
+			page.Stream = template.HTML("<pre>" + html.EscapeString(hex.Dump(preview)) + "</pre>")
+		}
+		page.Stream += template.HTML(fmt.Sprintf("<p><a href=\"%s\">decoded data</a>, %d bytes</p>", link, len(data)))
+	}
+	s.render(w, page)
+}
+
+func (s *server) stream(w http.ResponseWriter, r *http.Request) {
+	o := s.lookup(r, "/stream/")
+	if o == nil {
+		http.NotFound(w, r)
+		return
+	}
+	dict, st := o.Stream()
+	if st == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if dict == nil {
+		dict = pdf.NewDictionary(nil)
+	}
+	data := st.Data()
+	contentType := streamType(dict, data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if contentType == "image/png" {
+		img, _ := rawImage(dict, data)
+		buffer := bytes.Buffer{}
+		if err := png.Encode(&buffer, img); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = buffer.Bytes()
+	}
+	_, _ = w.Write(data)
+}
+
+func (s *server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	page := &serverPage{Title: "Search", Query: query}
+	if query != "" {
+		text := strings.ToLower(query)
+		for _, o := range s.doc.SortedObjects() {
+			rendering := o.String()
+			if _, st := o.Stream(); st != nil && printable(st.Data()) {
+				rendering += string(st.Data())
+			}
+			for _, line := range strings.Split(rendering, "\n") {
+				if strings.Contains(strings.ToLower(line), text) {
+					item := listObject(o)
+					item.Summary = strings.TrimSpace(line)
+					page.Results = append(page.Results, item)
+					break
+				}
+			}
+		}
+	}
+	s.render(w, page)
+}
+
+func serve(addr string, filePath string, doc *pdf.PDF) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	addr = net.JoinHostPort(host, port)
+	name := "stdin"
+	if filePath != "" && filePath != "-" {
+		name = filepath.Base(filePath)
+	}
+	log.Printf("serving %s on http://%s/", name, addr)
+	return http.ListenAndServe(addr, newServer(name, doc))
+}
+
+func runServe(name string, args []string) {
+	fs := newFlagSet(name)
+	addr := fs.String("addr", ":8080", "address to listen on, addresses without a host only accept local connections")
+	_ = fs.Parse(args)
+	input := inputArg(fs)
+	global.checkFormat(name)
+	doc := parseInput(input)
+	applyRules(doc)
+	if err := serve(*addr, input, doc); err != nil {
+		fatal("error:", err)
+	}
+}
+
+var serverTemplate = template.Must(template.New("server").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+header { display: flex; gap: 2em; align-items: baseline; }
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 0.8em; text-align: left; border-bottom: 1px solid #ddd; }
+ul.tree { list-style: none; padding-left: 1.5em; margin: 0; }
+.key { color: #7a3e9d; }
+.type { color: #777; }
+summary { cursor: pointer; }
+pre { background: #f6f6f6; padding: 1em; overflow: auto; max-height: 40em; }
+img { max-width: 100%; border: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<header>
+<h1><a href="/">{{.Name}}</a></h1>
+<form action="/search"><input name="q" value="{{.Query}}" placeholder="Search objects"> <button>Search</button></form>
+</header>
+<h2>{{.Title}}</h2>
+{{if .Trailer}}<h3>Trailer</h3>{{.Trailer}}{{end}}
+{{if .Pages}}<h3>Pages</h3>
+<table>{{range .Pages}}<tr><td><a href="{{.Link}}">{{.Type}}</a></td><td>{{.Label}}</td></tr>{{end}}</table>{{end}}
+{{if .Objects}}<h3>Objects</h3>
+<table><tr><th>Object</th><th>Type</th><th></th></tr>{{range .Objects}}<tr><td><a href="{{.Link}}">{{.Label}}</a></td><td>{{.Type}}</td><td>{{.Summary}}</td></tr>{{end}}</table>{{end}}
+{{if .Query}}<h3>Results</h3>
+{{if .Results}}<table>{{range .Results}}<tr><td><a href="{{.Link}}">{{.Label}}</a></td><td>{{.Type}}</td><td><code>{{.Summary}}</code></td></tr>{{end}}</table>{{else}}<p>No objects found.</p>{{end}}{{end}}
+{{if .Object}}{{.Object}}{{end}}
+{{if .Stream}}<h3>Stream</h3>{{.Stream}}{{end}}
+{{if .Referrer}}<h3>Referenced by</h3>
+<table>{{range .Referrer}}<tr><td><a href="{{.Link}}">{{.Label}}</a></td><td>{{.Type}}</td></tr>{{end}}</table>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"github.com/aelbrecht/pdfdump/internal/pdfgen"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	d := pdfgen.TextDocument([]string{"Hello <world>"})
+	script := d.AddStream("", []byte("<html><script>alert(1)</script></html>"))
+	img := d.AddFlateStream("/Type /XObject /Subtype /Image /Width 2 /Height 1 /BitsPerComponent 8 /ColorSpace /DeviceGray", []byte{0, 255})
+	doc, err := parse(d.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newServer("test.pdf", doc)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	for path, expected := range map[string][]string{
+		"/":               {"<a href=\"/object/1/0\">1 0</a>", "Page 1"},
+		"/object/5/0":     {"<a href=\"/object/2/0\">2 0 R</a>", "Referenced by"},
+		"/search?q=hello": {"Results", "Hello &lt;world&gt;"},
+	} {
+		w := get(path)
+		for _, e := range expected {
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), e) {
+				t.Errorf("%s: expected %q in\n%s", path, e, w.Body.String())
+			}
+		}
+	}
+
+	w := get("/stream/" + strings.Replace(pdfgen.Ref(img), " 0 R", "/0", 1))
+	if w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a png image, got %s", w.Header().Get("Content-Type"))
+	}
+	if _, err := png.Decode(w.Body); err != nil {
+		t.Error(err)
+	}
+	w = get("/stream/" + strings.Replace(pdfgen.Ref(script), " 0 R", "/0", 1))
+	if w.Header().Get("Content-Type") != "text/plain; charset=utf-8" || w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("expected a plain text stream that is not sniffed, got %v", w.Header())
+	}
+	if w := get("/object/99/0"); w.Code != http.StatusNotFound {
+		t.Errorf("expected not found, got %d", w.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/aelbrecht/pdfdump/external/pdf"
+)
+
+func runText(name string, args []string) {
+	fs := newFlagSet(name)
+	showPositions := fs.Bool("positions", false, "print the position and font size of every text span")
+	pageNumber := fs.Int("page", 0, "only print the given page")
+	_ = fs.Parse(args)
+
+	input := inputArg(fs)
+	global.checkFormat(name)
+	doc := parseInput(input)
+
+	pages := make([]*pdf.PageText, 0)
+	for _, page := range pdf.ExtractText(doc) {
+		if *pageNumber == 0 || page.Number == *pageNumber {
+			pages = append(pages, page)
+		}
+	}
+	if global.format == "json" {
+		if err := printJSON(pages); err != nil {
+			fatal("error:", err)
+		}
+		return
+	}
+	for _, page := range pages {
+		_, _ = fmt.Fprintf(stdout, "# Page %d\n", page.Number)
+		if *showPositions {
+			_, _ = fmt.Fprint(stdout, page.Positions())
+		} else {
+			_, _ = fmt.Fprintln(stdout, page.String())
+		}
+	}
+}
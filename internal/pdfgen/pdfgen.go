@@ -0,0 +1,128 @@
+// Package pdfgen writes small PDF documents for tests and benchmarks. Every
+// token is separated by a single space or line break, the layout the parser
+// of this module expects.
+package pdfgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+type Document struct {
+	objects []string
+	root    int
+	info    int
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// Reserve allocates an object number to be filled in later with Set.
+func (d *Document) Reserve() int {
+	d.objects = append(d.objects, "null")
+	return len(d.objects)
+}
+
+// Set replaces the body of a reserved object.
+func (d *Document) Set(n int, body string) {
+	d.objects[n-1] = body
+}
+
+// Add appends an object and returns its number.
+func (d *Document) Add(body string) int {
+	n := d.Reserve()
+	d.Set(n, body)
+	return n
+}
+
+// AddStream appends a stream object, entries are added to its dictionary.
+func (d *Document) AddStream(entries string, data []byte) int {
+	if entries != "" {
+		entries = " " + entries
+	}
+	return d.Add(fmt.Sprintf("<< /Length %d%s >>\nstream\n%s\nendstream", len(data), entries, data))
+}
+
+// AddFlateStream compresses the data and appends it as a stream object.
+func (d *Document) AddFlateStream(entries string, data []byte) int {
+	buffer := bytes.Buffer{}
+	w := zlib.NewWriter(&buffer)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	if entries != "" {
+		entries = " " + entries
+	}
+	return d.AddStream("/Filter /FlateDecode"+entries, buffer.Bytes())
+}
+
+func (d *Document) SetRoot(n int) {
+	d.root = n
+}
+
+func (d *Document) SetInfo(n int) {
+	d.info = n
+}
+
+// Ref formats an indirect reference to an object.
+func Ref(n int) string {
+	return fmt.Sprintf("%d 0 R", n)
+}
+
+func (d *Document) Bytes() []byte {
+	buffer := bytes.Buffer{}
+	buffer.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = buffer.Len()
+		buffer.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, body))
+	}
+	start := buffer.Len()
+	buffer.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f\n", len(d.objects)+1))
+	for _, offset := range offsets {
+		buffer.WriteString(fmt.Sprintf("%010d 00000 n\n", offset))
+	}
+	trailer := fmt.Sprintf("/Size %d", len(d.objects)+1)
+	if d.root != 0 {
+		trailer += " /Root " + Ref(d.root)
+	}
+	if d.info != 0 {
+		trailer += " /Info " + Ref(d.info)
+	}
+	buffer.WriteString(fmt.Sprintf("trailer\n<< %s >>\nstartxref\n%d\n%%%%EOF\n", trailer, start))
+	return buffer.Bytes()
+}
+
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "(", "\\(")
+	return strings.ReplaceAll(s, ")", "\\)")
+}
+
+// TextDocument builds a document with one page per entry, each page showing
+// its lines of text in Helvetica.
+func TextDocument(pages ...[]string) *Document {
+	d := New()
+	catalog := d.Reserve()
+	tree := d.Reserve()
+	font := d.Add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	kids := make([]string, 0, len(pages))
+	for _, lines := range pages {
+		content := strings.Builder{}
+		content.WriteString("BT\n/F1 12 Tf\n14 TL\n72 720 Td\n")
+		for _, line := range lines {
+			content.WriteString(fmt.Sprintf("(%s) Tj\nT*\n", escape(line)))
+		}
+		content.WriteString("ET")
+		stream := d.AddFlateStream("", []byte(content.String()))
+		page := d.Add(fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [ 0 0 612 792 ] /Resources << /Font << /F1 %s >> >> /Contents %s >>",
+			Ref(tree), Ref(font), Ref(stream)))
+		kids = append(kids, Ref(page))
+	}
+	d.Set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", Ref(tree)))
+	d.Set(tree, fmt.Sprintf("<< /Type /Pages /Kids [ %s ] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	d.SetRoot(catalog)
+	return d
+}
@@ -3,9 +3,7 @@ package token
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"io"
-	"os"
 	"strings"
 )
 
@@ -32,9 +30,23 @@ type Scanner struct {
 	history      [historySize]string
 	historyIndex int
 	end          bool
+	delimiter    byte
 }
 
-func NewScanner(r io.Reader) *Scanner {
+// SyntaxError reports malformed input, Context holds the lines read last with
+// the current one marked.
+type SyntaxError struct {
+	Message string
+	Context string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+// NewScanner reads the header of a document and returns a scanner of the
+// lines following it.
+func NewScanner(r io.Reader) (*Scanner, error) {
 
 	var b = make([]byte, 1)
 	var header = make([]byte, 0)
@@ -42,7 +54,7 @@ func NewScanner(r io.Reader) *Scanner {
 	for count < 2 {
 		_, err := r.Read(b)
 		if err != nil {
-			panic("could not read header")
+			return nil, &SyntaxError{Message: "could not read header"}
 		}
 		if b[0] == '%' {
 			count++
@@ -51,10 +63,10 @@ func NewScanner(r io.Reader) *Scanner {
 		header = append(header, b[0])
 	}
 
-	version := string(header[:len(header)-1])
-	if !strings.HasPrefix(version, "PDF") {
-		panic("invalid pdf header")
+	if len(header) < 2 || !strings.HasPrefix(string(header), "PDF") {
+		return nil, &SyntaxError{Message: "invalid pdf header"}
 	}
+	version := string(header[:len(header)-1])
 
 	delimiter := header[len(header)-1]
 	scanner := bufio.NewScanner(r)
@@ -65,21 +77,49 @@ func NewScanner(r io.Reader) *Scanner {
 	buffer := make([]byte, bufferSize)
 	scanner.Buffer(buffer, bufferSize)
 	t := &Scanner{
-		scanner: scanner,
-		version: version,
+		scanner:   scanner,
+		version:   version,
+		delimiter: delimiter,
 	}
+	if err := t.start(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// start reads the first line after the header, it recovers the errors of
+// reading the input.
+func (t *Scanner) start() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
 	t.scan() // Skip random characters in header
 	if !t.scan() {
-		panic("EOF")
+		return &SyntaxError{Message: "unexpected EOF"}
 	}
-	return t
+	return nil
 }
 
 func (t *Scanner) HasToken() bool {
 	return !t.end
 }
 
-func (t *Scanner) Dump() {
+// Fail stops scanning with a syntax error holding the lines around the
+// current token, parsers recover it to return the error.
+func (t *Scanner) Fail(message string) {
+	panic(&SyntaxError{Message: message, Context: t.Context()})
+}
+
+// Context renders the lines read last, the current line is marked with an
+// arrow and followed by up to two lines ahead.
+func (t *Scanner) Context() string {
+	buffer := strings.Builder{}
 	i := t.historyIndex
 	for true {
 		offset := 0
@@ -87,11 +127,11 @@ func (t *Scanner) Dump() {
 			offset = 1
 		}
 		if i != (t.historyIndex+historySize-1-offset)%historySize {
-			fmt.Print("#####")
+			buffer.WriteString("#####")
 		} else {
-			fmt.Print("---->")
+			buffer.WriteString("---->")
 		}
-		fmt.Println("   " + t.history[i])
+		buffer.WriteString("   " + t.history[i] + "\n")
 		i = (i + 1) % historySize
 		if t.historyIndex == i {
 			break
@@ -99,11 +139,11 @@ func (t *Scanner) Dump() {
 	}
 	for j := 0; j < 2; j++ {
 		if !t.scanner.Scan() {
-			return
+			break
 		}
-		fmt.Print("#####")
-		fmt.Println("   " + t.scanner.Text())
+		buffer.WriteString("#####   " + t.scanner.Text() + "\n")
 	}
+	return buffer.String()
 }
 
 func (t *Scanner) Next() string {
@@ -112,7 +152,7 @@ func (t *Scanner) Next() string {
 	if t.index >= len(t.tokens) {
 		if !t.scan() {
 			if t.end {
-				panic("unexpected EOF")
+				t.Fail("unexpected EOF")
 			}
 			t.end = true
 		}
@@ -120,6 +160,25 @@ func (t *Scanner) Next() string {
 	return next
 }
 
+// NextLine consumes the remaining tokens of the current line and returns them
+// as they appeared in the input, without the line delimiter.
+func (t *Scanner) NextLine() string {
+	line := strings.Join(t.tokens[t.index:], " ")
+	t.index = len(t.tokens)
+	if !t.scan() {
+		if t.end {
+			t.Fail("unexpected EOF")
+		}
+		t.end = true
+	}
+	return line
+}
+
+// Delimiter returns the byte that separates lines in the input.
+func (t *Scanner) Delimiter() byte {
+	return t.delimiter
+}
+
 func (t *Scanner) HasSuffix(suffix string) bool {
 	return t.tokens[len(t.tokens)-1] == suffix
 }
@@ -138,9 +197,7 @@ func (t *Scanner) Pop(token string) bool {
 
 func (t *Scanner) Peek() string {
 	if t.index >= len(t.tokens) {
-		fmt.Println("error: token out of bounds")
-		t.Dump()
-		os.Exit(1)
+		t.Fail("token out of bounds")
 	}
 	return strings.TrimSpace(t.tokens[t.index])
 }
@@ -165,7 +222,7 @@ func (t *Scanner) scan() bool {
 	if !t.scanner.Scan() {
 		err := t.scanner.Err()
 		if err != nil {
-			panic(err)
+			panic(&SyntaxError{Message: err.Error()})
 		}
 		return false
 	}